@@ -0,0 +1,141 @@
+// gridbench - ProfessionalGrid, AdaptiveGrid, FixedGrid ve MarketProfileGrid'i sentetik OHLCV verisi
+// üzerinde karşılaştırır.
+//
+// Bu adlarda Go stratejisi yoktur ve GridPro banbot motoru olmadan çalışamaz; her strateji
+// benchStrategies'teki gridsim ayarıyla, seviye kurulumu ve stop/hedef kuralları
+// gridsim.SimulateGrid ile simüle edilir.
+//
+// Depo go.mod içermez. Çalıştırmak için depo kökünde modül oluşturulmalıdır:
+//
+//	go mod init github.com/anbarci/anbarci231 && go mod tidy
+//	go run ./cmd/gridbench
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+
+	"github.com/anbarci/anbarci231/gridsim"
+)
+
+// benchStrategies - istenen dört strateji ve simülasyondaki karşılıkları
+var benchStrategies = []struct {
+	name   string
+	mode   string
+	anchor string
+}{
+	{"ProfessionalGrid", gridsim.ModeATR, gridsim.AnchorStatic}, // GridPro, ATR Based
+	{"AdaptiveGrid", gridsim.ModeATR, gridsim.AnchorClose},      // grid boşken base fiyata taşınır (MultiLayerGrid katmanları gibi)
+	{"FixedGrid", gridsim.ModeFixed, gridsim.AnchorStatic},      // GridPro, Fixed Spacing
+	{"MarketProfileGrid", gridsim.ModeFixed, gridsim.AnchorPOC}, // base son 100 barın TPO POC'u
+}
+
+func main() {
+	bars := flag.Int("bars", 2000, "number of synthetic bars per scenario")
+	seed := flag.Int64("seed", 42, "random seed")
+	robustIters := flag.Int("robust-iters", 0, "parameter perturbation runs per strategy (0 = skip robustness test)")
+	perturbPct := flag.Float64("perturb-pct", 10, "max parameter perturbation (%) for the robustness test")
+	sensitivity := flag.Bool("sensitivity", false, "rank the 5 parameters with the largest Sharpe impact per strategy")
+	flag.Parse()
+
+	scenarios := []struct {
+		name  string
+		trend float64 // bar başına fiyat değişimi (%)
+	}{
+		{"range", 0},
+		{"trend", 0.02},
+	}
+
+	type robustRow struct {
+		label  string
+		report gridsim.RobustnessReport
+	}
+	var robust []robustRow
+	type sensitivityRow struct {
+		label   string
+		results []gridsim.SensitivityResult
+	}
+	var sensitive []sensitivityRow
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "scenario\tstrategy\ttrades\twin rate\tsharpe\tmax dd\ttotal pnl\t")
+	for _, sc := range scenarios {
+		// Her senaryo aynı seed ile üretilir, stratejiler aynı veriyi görür
+		data := syntheticBars(*bars, sc.trend, rand.New(rand.NewSource(*seed)))
+		for _, st := range benchStrategies {
+			cfg := gridsim.Config{
+				Mode:          st.mode,
+				GridCount:     8,
+				SpacingPct:    1.0,
+				ATRPeriod:     14,
+				ATRMultiplier: 1.5,
+				StopLossATR:   2.0,
+				TakeProfitATR: 3.0,
+				PositionCost:  10000 * 0.05 / 8,
+				BarSecs:       3600,
+				Anchor:        st.anchor,
+				ProfileBars:   100,
+			}
+			res := gridsim.SimulateGrid(data, cfg)
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.1f%%\t%.2f\t%.2f\t%.2f\t\n", sc.name, st.name, res.Trades,
+				res.WinRate*100, res.Sharpe, res.MaxDrawdown, res.TotalPnL)
+			if *robustIters > 0 {
+				report := gridsim.RobustnessTest(data, cfg, *perturbPct, *robustIters, rand.New(rand.NewSource(*seed)))
+				robust = append(robust, robustRow{sc.name + "\t" + st.name, report})
+			}
+			if *sensitivity {
+				sensitive = append(sensitive, sensitivityRow{sc.name + "\t" + st.name, gridsim.RankSensitivity(data, cfg, 5)})
+			}
+		}
+	}
+	w.Flush()
+
+	if *robustIters > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "scenario\tstrategy\tmean sharpe\tstd dev\tworst\tbest\trobustness\t")
+		for _, row := range robust {
+			r := row.report
+			fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.0f%%\t\n", row.label, r.MeanSharpe, r.SharpeStdDev,
+				r.WorstSharpe, r.BestSharpe, r.RobustnessScore)
+		}
+		w.Flush()
+	}
+
+	if *sensitivity {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "scenario\tstrategy\trank\tparam\telasticity\t")
+		for _, row := range sensitive {
+			for rank, res := range row.results {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%.3f\t\n", row.label, rank+1, res.Param, res.Elasticity)
+			}
+		}
+		w.Flush()
+	}
+}
+
+// syntheticBars - random walk + sinüs (yatay piyasa) + doğrusal trend
+func syntheticBars(n int, trendPct float64, rng *rand.Rand) []gridsim.Bar {
+	res := make([]gridsim.Bar, n)
+	walk := 0.0
+	prevClose := 100.0
+	for i := range res {
+		walk += rng.NormFloat64() * 0.3
+		price := 100 + walk + 5*math.Sin(float64(i)*2*math.Pi/200)
+		price *= 1 + trendPct/100*float64(i)
+		price = math.Max(price, 1)
+		wick := math.Abs(rng.NormFloat64()) * 0.4
+		res[i] = gridsim.Bar{
+			Open:  prevClose,
+			High:  math.Max(prevClose, price) + wick,
+			Low:   math.Max(math.Min(prevClose, price)-wick, 0.5),
+			Close: price,
+		}
+		prevClose = price
+	}
+	return res
+}
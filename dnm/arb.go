@@ -0,0 +1,241 @@
+package dnm
+
+import (
+	"math"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+)
+
+// ArbitrageLeg - arbitraj çiftinin exchange_b'deki bacağı
+type ArbitrageLeg struct {
+	Exchange string
+	Symbol   string
+	Tag      string
+	Short    bool
+	Amount   float64
+	Price    float64
+	Close    bool // true ise aynı tag'li bacak kapatılır
+}
+
+// simulateArbitrageLeg - backtest'te GridConfig.OnArbitrageLeg verilmediğinde kullanılır;
+// bacağı loglar ve dolmuş sayar
+func simulateArbitrageLeg(s *strat.StratJob, leg ArbitrageLeg) error {
+	action := "open"
+	if leg.Close {
+		action = "close"
+	}
+	s.Infof("Arbitrage leg %s on %s: %s short=%v amount=%.4f price=%.4f", action, leg.Exchange, leg.Tag, leg.Short, leg.Amount, leg.Price)
+	return nil
+}
+
+// arbLegHook - exchange_b bacağını yerleştiren hook; canlıda hook verilmediyse nil döner ve işlem yapılmaz
+func arbLegHook(cfg GridConfig, backtest bool) func(s *strat.StratJob, leg ArbitrageLeg) error {
+	if cfg.OnArbitrageLeg != nil {
+		return cfg.OnArbitrageLeg
+	}
+	if backtest {
+		return simulateArbitrageLeg
+	}
+	return nil
+}
+
+// arbPair - açık arbitraj çifti; job'un borsasındaki bacak emir olarak, exchange_b bacağı burada tutulur
+type arbPair struct {
+	order      *core.Order // local emir henüz job'un emir listesinde görünmüyorsa nil, tag ile sonradan bulunur
+	tag        string
+	openedBar  int
+	shortLocal bool // job'un borsası pahalı taraftaysa local bacak short
+	amount     float64
+	entryLocal float64
+	entryOther float64
+}
+
+// arbResolveBars - local emri bu kadar bar içinde bulunamayan çiftin exchange_b bacağı geri kapatılır
+const arbResolveBars = 3
+
+// arbOrder - tag'i birebir eşleşen açık emir (B1 öneki B10'u da kapsadığından önek araması kullanılmaz)
+func arbOrder(s *strat.StratJob, tag string) *core.Order {
+	for _, order := range GridOpenOrders(s, tag) {
+		if order.Tag == tag {
+			return order
+		}
+	}
+	return nil
+}
+
+// arbSpreadPct - exchange_b fiyatının job borsası fiyatına göre farkı (%)
+func arbSpreadPct(local, other float64) float64 {
+	if local <= 0 {
+		return math.NaN()
+	}
+	return (other - local) / local * 100
+}
+
+// arbPairPnL - iki bacağın PnL'i, 4 işlem ücreti ve transfer maliyeti düşülmüş olarak
+func arbPairPnL(pair arbPair, exitLocal, exitOther, feePct, transferCostPct float64) float64 {
+	local := (exitLocal - pair.entryLocal) * pair.amount
+	other := (pair.entryOther - exitOther) * pair.amount
+	if pair.shortLocal {
+		local, other = -local, -other
+	}
+	notional := (pair.entryLocal + pair.entryOther + exitLocal + exitOther) * pair.amount
+	return local + other - notional*feePct/100 - pair.entryLocal*pair.amount*transferCostPct/100
+}
+
+// ArbitrageGrid - job'un borsası (exchange_a) ile exchange_b arasındaki fiyat farkı kademeli eşikleri
+// aştıkça ucuz tarafta alış, pahalı tarafta satış açar; fark exit eşiğine dönünce iki bacak birlikte kapanır.
+// Kademe i, fark i * arb_threshold_pct'yi aştığında açılır. Backtest'te exchange_b fiyatı aynı OHLCV'ye
+// sinüs biçimli sentetik fark eklenerek üretilir.
+func ArbitrageGrid(pol *config.RunPolicyConfig) *strat.TradeStrat {
+	return ArbitrageGridWithConfig(pol, GridConfig{})
+}
+
+// ArbitrageGridWithConfig - exchange_b fiyat kaynağını ve bacak hook'unu cfg'den alan ArbitrageGrid
+func ArbitrageGridWithConfig(pol *config.RunPolicyConfig, cfg GridConfig) *strat.TradeStrat {
+
+	instanceID := string(pol.Def("instance_id", "arb"))
+	exchangeA := string(pol.Def("exchange_a", "binance"))
+	exchangeB := string(pol.Def("exchange_b", "okx"))
+	thresholdPct := float64(pol.Def("arb_threshold_pct", 0.1, core.PNorm(0.05, 1.0)))
+	exitPct := float64(pol.Def("arb_exit_pct", 0.02, core.PNorm(0.0, 0.1)))
+	arbLevels := int(pol.Def("arb_levels", 3, core.PNorm(1, 10)))
+	feePct := float64(pol.Def("fee_pct", 0.1, core.PNorm(0.0, 0.5)))
+	transferCostPct := float64(pol.Def("transfer_cost_pct", 0.05, core.PNorm(0.0, 0.5)))
+	simSpreadPct := float64(pol.Def("sim_spread_pct", 0.3, core.PNorm(0.0, 2.0)))
+	simSpreadPeriod := int(pol.Def("sim_spread_period", 50, core.PNorm(10, 200)))
+
+	initialCapital := float64(pol.Def("initial_capital", 10000.0))
+	maxSinglePosition := float64(pol.Def("max_single_position", 5.0, core.PNorm(1.0, 10.0)))
+
+	pairs := make(map[int]arbPair) // kademe -> açık çift
+	var totalNetPnl float64 = 0
+	var quoteMissing bool = false
+	var legHookMissing bool = false
+
+	return &strat.TradeStrat{
+		WarmupNum:     gridWarmupNum,
+		StopEnterBars: validateStopEnterBars(pol),
+
+		OnBar: func(s *strat.StratJob) {
+			e := s.Env
+			if e.Close.Len() < 2 {
+				return
+			}
+			localPrice := e.Close.Last(0)
+
+			placeLeg := arbLegHook(cfg, core.BacktestMode)
+			if placeLeg == nil {
+				if !legHookMissing {
+					s.Infof("Arbitrage grid: no %s leg hook for %s, trading disabled outside backtest", exchangeB, s.Symbol)
+					legHookMissing = true
+				}
+				return
+			}
+
+			var otherPrice float64
+			if core.BacktestMode {
+				wave := math.Sin(2 * math.Pi * float64(e.BarIndex) / float64(max(simSpreadPeriod, 2)))
+				otherPrice = localPrice * (1 + simSpreadPct/100*wave)
+			} else {
+				price, ok := 0.0, false
+				if cfg.ArbQuoteSource != nil {
+					price, ok = cfg.ArbQuoteSource(exchangeB, s.Symbol)
+				}
+				if !ok || price <= 0 {
+					if !quoteMissing {
+						s.Infof("Arbitrage grid: no %s quote for %s, trading paused", exchangeB, s.Symbol)
+						quoteMissing = true
+					}
+					return
+				}
+				quoteMissing = false
+				otherPrice = price
+			}
+			spread := arbSpreadPct(localPrice, otherPrice)
+			if math.IsNaN(spread) {
+				return
+			}
+
+			// Local emri henüz eşlenmemiş çiftleri tag ile bul; bulunamazsa exchange_b bacağı açıkta kalmasın
+			for level, pair := range pairs {
+				if pair.order != nil {
+					continue
+				}
+				if pair.order = arbOrder(s, pair.tag); pair.order != nil {
+					pairs[level] = pair
+					continue
+				}
+				if e.BarIndex-pair.openedBar < arbResolveBars {
+					continue
+				}
+				if err := placeLeg(s, ArbitrageLeg{Exchange: exchangeB, Symbol: s.Symbol, Tag: pair.tag,
+					Short: !pair.shortLocal, Amount: pair.amount, Price: otherPrice, Close: true}); err != nil {
+					s.Infof("Arbitrage %s leg close failed for %s: %v", exchangeB, pair.tag, err)
+					continue
+				}
+				delete(pairs, level)
+				s.Infof("Arbitrage pair %d aborted: %s leg for %s not found after %d bars", level, exchangeA, pair.tag, arbResolveBars)
+			}
+
+			// Fark kapandıysa iki bacağı birlikte kapat
+			if math.Abs(spread) <= exitPct {
+				for level, pair := range pairs {
+					if pair.order == nil {
+						continue // local bacak henüz eşlenmedi
+					}
+					tag := pair.tag
+					if err := placeLeg(s, ArbitrageLeg{Exchange: exchangeB, Symbol: s.Symbol, Tag: tag,
+						Short: !pair.shortLocal, Amount: pair.amount, Price: otherPrice, Close: true}); err != nil {
+						s.Infof("Arbitrage %s leg close failed for %s: %v", exchangeB, tag, err)
+						continue
+					}
+					s.CloseOrders(&strat.ExitReq{Tag: "arb_exit_" + tag, Orders: []*core.Order{pair.order}})
+					pnl := arbPairPnL(pair, localPrice, otherPrice, feePct, transferCostPct)
+					totalNetPnl += pnl
+					delete(pairs, level)
+					s.Infof("Arbitrage pair %d closed: spread %.3f%%, net PnL %.4f (total %.4f)", level, spread, pnl, totalNetPnl)
+				}
+			}
+
+			// Her kademe, fark i * eşiği aştığında bir çift açar
+			shortLocal := spread < 0 // job'un borsası pahalıysa burada satılır, exchange_b'de alınır
+			size := initialCapital * (maxSinglePosition / 100) / float64(arbLevels) / localPrice
+			for level := 1; level <= arbLevels; level++ {
+				if _, open := pairs[level]; open || math.Abs(spread) < thresholdPct*float64(level) {
+					continue
+				}
+				levelType := LevelBuy
+				if shortLocal {
+					levelType = LevelSell
+				}
+				tag := levelTag(instanceID, levelType, level)
+				if err := s.OpenOrder(&strat.EnterReq{Tag: tag, Short: shortLocal, Amount: size}); err != nil {
+					s.Infof("Arbitrage %s leg not opened for %s: %v", exchangeA, tag, err)
+					continue
+				}
+				if err := placeLeg(s, ArbitrageLeg{Exchange: exchangeB, Symbol: s.Symbol, Tag: tag,
+					Short: !shortLocal, Amount: size, Price: otherPrice}); err != nil {
+					// Tek bacak açık kalmasın
+					s.Infof("Arbitrage %s leg not opened for %s: %v", exchangeB, tag, err)
+					if order := arbOrder(s, tag); order != nil {
+						s.CloseOrders(&strat.ExitReq{Tag: "arb_abort_" + tag, Orders: []*core.Order{order}})
+					}
+					continue
+				}
+				// Local emir bu bar görünmüyorsa çift yine kaydedilir; exchange_b bacağı açık olduğundan
+				// emir sonraki barlarda tag ile eşlenir, eşlenemezse bacak geri kapatılır
+				pairs[level] = arbPair{order: arbOrder(s, tag), tag: tag, openedBar: e.BarIndex, shortLocal: shortLocal,
+					amount: size, entryLocal: localPrice, entryOther: otherPrice}
+				s.Infof("Arbitrage pair %d opened: %s %.4f vs %s %.4f (spread %.3f%%)",
+					level, exchangeA, localPrice, exchangeB, otherPrice, spread)
+			}
+
+			if e.BarIndex%100 == 0 {
+				s.Infof("Arbitrage Grid: %s %.4f, %s %.4f, Spread=%.3f%%, Pairs=%d, Net PnL=%.4f",
+					exchangeA, localPrice, exchangeB, otherPrice, spread, len(pairs), totalNetPnl)
+			}
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package dnm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// Bacak hook'u verilmediyse canlıda işlem yapılmamalı; backtest'te bacak simüle edilir
+func TestArbLegHook(t *testing.T) {
+	errPlaced := errors.New("placed")
+	custom := GridConfig{OnArbitrageLeg: func(*strat.StratJob, ArbitrageLeg) error { return errPlaced }}
+	tests := []struct {
+		name     string
+		cfg      GridConfig
+		backtest bool
+		wantNil  bool
+		wantErr  error
+	}{
+		{name: "live without hook", wantNil: true},
+		{name: "backtest without hook", backtest: true},
+		{name: "live with hook", cfg: custom, wantErr: errPlaced},
+		{name: "backtest with hook", cfg: custom, backtest: true, wantErr: errPlaced},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := arbLegHook(tt.cfg, tt.backtest)
+			if (hook == nil) != tt.wantNil {
+				t.Fatalf("hook nil = %v, want %v", hook == nil, tt.wantNil)
+			}
+			if hook == nil {
+				return
+			}
+			if err := hook(&strat.StratJob{}, ArbitrageLeg{Tag: levelTag("arb", LevelBuy, 1)}); err != tt.wantErr {
+				t.Errorf("hook error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
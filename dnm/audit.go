@@ -0,0 +1,106 @@
+package dnm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/banbox/banbot/core"
+)
+
+// Audit olay tipleri
+const (
+	AuditEventOpen  = "open"
+	AuditEventClose = "close"
+)
+
+// AuditEntry - audit log'a yazılan tek emir olayı
+type AuditEntry struct {
+	Timestamp     int64   `json:"timestamp"`
+	EventType     string  `json:"event_type"`
+	LevelName     string  `json:"level_name"` // bağlı grid seviyesi yoksa boş
+	OrderTag      string  `json:"order_tag"`
+	Price         float64 `json:"price"`
+	Size          float64 `json:"size"`
+	PnL           float64 `json:"pnl"`            // yalnızca close olaylarında
+	PortfolioRisk float64 `json:"portfolio_risk"` // açık pozisyon maliyeti / sermaye (%)
+}
+
+// GridOrderAuditLog - grid emirlerinin açılış/kapanışlarını bar bazında tespit edip JSONL dosyasına ekler.
+// Emirler bellekte biriktirilir, Flush çağrılınca dosyaya yazılır.
+type GridOrderAuditLog struct {
+	path       string
+	instanceID string
+	known      map[*core.Order]struct{}
+	pending    []AuditEntry
+}
+
+func NewGridOrderAuditLog(path, instanceID string) *GridOrderAuditLog {
+	return &GridOrderAuditLog{path: path, instanceID: instanceID, known: make(map[*core.Order]struct{})}
+}
+
+// Observe - açık emirleri önceki barla karşılaştırır; yeni emirler open, kaybolanlar close olarak kaydedilir.
+// Kapanan emrin fiyatı bilinmediği için güncel fiyat yazılır.
+func (l *GridOrderAuditLog) Observe(timestamp int64, orders []*core.Order, price, portfolioRisk float64) {
+	current := make(map[*core.Order]struct{}, len(orders))
+	for _, order := range orders {
+		current[order] = struct{}{}
+		if _, ok := l.known[order]; ok {
+			continue
+		}
+		openPrice := order.AvgPrice
+		if openPrice <= 0 {
+			openPrice = price
+		}
+		l.pending = append(l.pending, l.entry(timestamp, AuditEventOpen, order, openPrice, 0, portfolioRisk))
+	}
+	for order := range l.known {
+		if _, ok := current[order]; !ok {
+			l.pending = append(l.pending, l.entry(timestamp, AuditEventClose, order, price, order.RealizedPnl, portfolioRisk))
+		}
+	}
+	l.known = current
+}
+
+func (l *GridOrderAuditLog) entry(timestamp int64, event string, order *core.Order, price, pnl, risk float64) AuditEntry {
+	name, _ := levelNameFromTag(order.Tag, l.instanceID)
+	return AuditEntry{
+		Timestamp:     timestamp,
+		EventType:     event,
+		LevelName:     name,
+		OrderTag:      order.Tag,
+		Price:         price,
+		Size:          order.Amount,
+		PnL:           pnl,
+		PortfolioRisk: risk,
+	}
+}
+
+// Flush - biriken kayıtları dosyanın sonuna satır satır JSON olarak ekler
+func (l *GridOrderAuditLog) Flush() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, item := range l.pending {
+		if err := enc.Encode(item); err != nil {
+			f.Close()
+			return fmt.Errorf("encode audit entry: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+	l.pending = l.pending[:0]
+	return nil
+}
@@ -0,0 +1,74 @@
+package dnm
+
+// backfillBar - backfill simülasyonu için geçmiş bar
+type backfillBar struct {
+	High, Low, Close float64
+}
+
+// backfillPosition - backfill simülasyonunda açık pozisyon
+type backfillPosition struct {
+	level  string
+	short  bool
+	entry  float64
+	amount float64
+}
+
+// BackfillResult - mevcut grid'in geçmiş barlarda çalışsaydı üreteceği sentetik işlemler.
+// Hiçbir emir açılmaz; canlı istatistiklerle karıştırılmamalıdır.
+type BackfillResult struct {
+	Trades      int
+	RealizedPnL float64
+	OpenPnL     float64 // pencere sonunda kapanmamış pozisyonların son kapanıştaki değeri
+}
+
+// backfillGrid - seviyeleri verilen barlarda (en eski önce) sabit tutarak dolumları simüle eder.
+// Tetiklenen seviye spacing kadar ilerideki komşu seviyede kapanır; aynı seviye kapanmadan tekrar dolmaz.
+// Giriş barında çıkış kontrol edilmez. positionCost seviye başına quote tutarıdır.
+func backfillGrid(levels map[string]GridLevel, bars []backfillBar, spacing, positionCost float64) BackfillResult {
+	var res BackfillResult
+	if spacing <= 0 || positionCost <= 0 || len(bars) == 0 {
+		return res
+	}
+	open := make(map[string]backfillPosition)
+	for _, bar := range bars {
+		for name, pos := range open {
+			exitPrice := pos.entry + spacing
+			hit := bar.High >= exitPrice
+			if pos.short {
+				exitPrice = pos.entry - spacing
+				hit = bar.Low <= exitPrice
+			}
+			if hit {
+				res.Trades++
+				res.RealizedPnL += spacing * pos.amount
+				delete(open, name)
+			}
+		}
+		for name, level := range levels {
+			if _, holding := open[name]; holding || !level.Active || level.Price <= 0 {
+				continue
+			}
+			triggered := bar.Low <= level.Price
+			if level.Type == LevelSell {
+				triggered = bar.High >= level.Price
+			}
+			if triggered {
+				open[name] = backfillPosition{
+					level:  name,
+					short:  level.Type == LevelSell,
+					entry:  level.Price,
+					amount: positionCost * level.SizeMultiplier / level.Price,
+				}
+			}
+		}
+	}
+	last := bars[len(bars)-1].Close
+	for _, pos := range open {
+		pnl := (last - pos.entry) * pos.amount
+		if pos.short {
+			pnl = -pnl
+		}
+		res.OpenPnL += pnl
+	}
+	return res
+}
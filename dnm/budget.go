@@ -0,0 +1,98 @@
+package dnm
+
+import (
+	"math"
+	"sync"
+)
+
+// BudgetAllocator - toplam sermayeyi grid stratejileri arasında Sharpe oranlarıyla orantılı paylaştırır.
+// Her strateji en az minAllocPct alır; Sharpe'ı pozitif olmayanlar yalnızca bu tabanı alır.
+type BudgetAllocator struct {
+	mu           sync.Mutex
+	keys         []string
+	strategies   []*GridStats
+	totalCapital float64
+	minAllocPct  float64
+}
+
+func NewBudgetAllocator(minAllocPct float64) *BudgetAllocator {
+	return &BudgetAllocator{minAllocPct: minAllocPct}
+}
+
+// gridBudget - realloc_interval_bars açık GridPro instance'larının ortak dağıtıcısı
+var gridBudget = NewBudgetAllocator(5.0)
+
+// Register - stratejiyi key ile ekler ve sermayesini toplama katar; key zaten kayıtlıysa istatistiği günceller
+func (b *BudgetAllocator) Register(key string, stats *GridStats, capital float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, k := range b.keys {
+		if k == key {
+			b.strategies[i] = stats
+			return
+		}
+	}
+	b.keys = append(b.keys, key)
+	b.strategies = append(b.strategies, stats)
+	b.totalCapital += capital
+}
+
+// SetMinAllocPct - strateji başına taban payı değiştirir
+func (b *BudgetAllocator) SetMinAllocPct(pct float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.minAllocPct = pct
+}
+
+// Len - kayıtlı strateji sayısı
+func (b *BudgetAllocator) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.keys)
+}
+
+// Allocate - key -> ayrılan sermaye. Önce herkese minAllocPct taban verilir, kalan sermaye
+// max(0, Sharpe) ağırlıklarıyla bölünür. Hiçbir Sharpe pozitif değilse ya da tabanlar toplamı
+// sermayeyi aşıyorsa eşit dağıtılır.
+func (b *BudgetAllocator) Allocate() map[string]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(b.strategies)
+	res := make(map[string]float64, n)
+	if n == 0 {
+		return res
+	}
+	floor := b.totalCapital * b.minAllocPct / 100
+	remaining := b.totalCapital - floor*float64(n)
+	weights := make([]float64, n)
+	sum := 0.0
+	for i, stats := range b.strategies {
+		if !math.IsNaN(stats.Sharpe) {
+			weights[i] = math.Max(0, stats.Sharpe)
+		}
+		sum += weights[i]
+	}
+	for i, key := range b.keys {
+		switch {
+		case remaining < 0:
+			res[key] = b.totalCapital / float64(n)
+		case sum == 0:
+			res[key] = floor + remaining/float64(n)
+		default:
+			res[key] = floor + remaining*weights[i]/sum
+		}
+	}
+	return res
+}
+
+// allocationScale - ayrılan sermayenin eşit paya oranı; max_single_position bu oranla ölçeklenir
+func (b *BudgetAllocator) allocationScale(key string) float64 {
+	alloc := b.Allocate()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	amount, ok := alloc[key]
+	if !ok || b.totalCapital <= 0 {
+		return 1.0
+	}
+	return amount / (b.totalCapital / float64(len(b.keys)))
+}
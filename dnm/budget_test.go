@@ -0,0 +1,49 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBudgetAllocator(t *testing.T) {
+	tests := []struct {
+		name    string
+		sharpes []float64
+		want    []float64 // strateji başına 1000, toplam 3000, taban %5 = 150
+	}{
+		{name: "sharpe 1.0, 0.5, -0.2", sharpes: []float64{1.0, 0.5, -0.2}, want: []float64{1850, 1000, 150}},
+		{name: "no positive sharpe", sharpes: []float64{-1, 0, math.NaN()}, want: []float64{1000, 1000, 1000}},
+	}
+	keys := []string{"a", "b", "c"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBudgetAllocator(5)
+			for i, sharpe := range tt.sharpes {
+				b.Register(keys[i], &GridStats{Sharpe: sharpe}, 1000)
+			}
+			alloc := b.Allocate()
+			total := 0.0
+			for i, key := range keys {
+				assertFloat(t, key, alloc[key], tt.want[i])
+				total += alloc[key]
+			}
+			assertFloat(t, "total", total, 3000)
+		})
+	}
+}
+
+func TestBudgetAllocatorScale(t *testing.T) {
+	b := NewBudgetAllocator(5)
+	stats := &GridStats{Sharpe: 1}
+	b.Register("a", stats, 1000)
+	b.Register("b", &GridStats{Sharpe: 1}, 1000)
+	assertFloat(t, "equal sharpe", b.allocationScale("a"), 1)
+
+	// Yeniden kayıt sermayeyi ikinci kez eklemez, yalnızca istatistiği günceller
+	b.Register("a", &GridStats{Sharpe: 3}, 1000)
+	if b.Len() != 2 {
+		t.Errorf("Len = %d, want 2", b.Len())
+	}
+	assertFloat(t, "a after update", b.allocationScale("a"), (100+1800*0.75)/1000)
+	assertFloat(t, "unknown key", b.allocationScale("x"), 1)
+}
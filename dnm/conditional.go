@@ -0,0 +1,80 @@
+package dnm
+
+import (
+	"math"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+	ta "github.com/banbox/banta"
+)
+
+// Hazır aktivasyon koşulları (activation_mode)
+const (
+	ActivationImmediate      = 1
+	ActivationEMAAligned     = 2 // hızlı, orta ve yavaş EMA aynı yönde sıralı
+	ActivationRangeConfirmed = 3 // ATR activation_range_bars bar boyunca düşük
+)
+
+// ConditionalGrid - GridPro ile aynıdır, ancak grid activationFunc true dönene kadar kurulmaz.
+// activationFunc nil ise activation_mode ile seçilen hazır koşul kullanılır.
+// Grid durdurulup yeniden başlatıldığında koşul tekrar beklenir.
+func ConditionalGrid(pol *config.RunPolicyConfig, activationFunc func(s *strat.StratJob) bool) *strat.TradeStrat {
+	if activationFunc == nil {
+		activationFunc = activationByMode(pol)
+	}
+	return newGridPro(pol, activationFunc, GridConfig{})
+}
+
+// makeConditionalGrid - strateji grubu kaydı için hazır koşullu ConditionalGrid
+func makeConditionalGrid(pol *config.RunPolicyConfig) *strat.TradeStrat {
+	return ConditionalGrid(pol, nil)
+}
+
+// activationByMode - activation_mode parametresine göre hazır aktivasyon koşulu
+func activationByMode(pol *config.RunPolicyConfig) func(s *strat.StratJob) bool {
+	mode := int(pol.Def("activation_mode", ActivationImmediate))
+	switch mode {
+	case ActivationEMAAligned:
+		return func(s *strat.StratJob) bool {
+			fast := ta.EMA(s.Env.Close, 9)
+			medium := ta.EMA(s.Env.Close, 21)
+			slow := ta.EMA(s.Env.Close, 50)
+			return emasAligned(fast, medium, slow)
+		}
+	case ActivationRangeConfirmed:
+		atrPeriod := int(pol.Def("atr_period", 14, core.PNorm(5, 50)))
+		rangeBars := int(pol.Def("activation_range_bars", 20, core.PNorm(5, 100)))
+		maxATRPct := float64(pol.Def("activation_max_atr_pct", 1.0, core.PNorm(0.2, 3.0)))
+		confirm := &rangeConfirmation{maxATRPct: maxATRPct, needBars: rangeBars}
+		return func(s *strat.StratJob) bool {
+			e := s.Env
+			return confirm.update(ta.ATR(e.High, e.Low, e.Close, atrPeriod) / e.Close.Last(0) * 100)
+		}
+	}
+	return func(*strat.StratJob) bool {
+		return true
+	}
+}
+
+// emasAligned - hızlı, orta ve yavaş EMA aynı yönde sıralı mı?
+func emasAligned(fast, medium, slow float64) bool {
+	return (fast > medium && medium > slow) || (fast < medium && medium < slow)
+}
+
+// rangeConfirmation - ATR yüzdesi needBars bar üst üste maxATRPct altında kaldı mı?
+type rangeConfirmation struct {
+	maxATRPct float64
+	needBars  int
+	lowBars   int
+}
+
+// update - barın ATR yüzdesini ekler; yüksek (ya da NaN) ATR sayacı sıfırlar
+func (r *rangeConfirmation) update(atrPct float64) bool {
+	if math.IsNaN(atrPct) || atrPct >= r.maxATRPct {
+		r.lowBars = 0
+		return false
+	}
+	r.lowBars++
+	return r.lowBars >= r.needBars
+}
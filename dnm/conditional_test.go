@@ -0,0 +1,47 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/strat"
+)
+
+func TestActivationModes(t *testing.T) {
+	immediate := activationByMode(&config.RunPolicyConfig{})
+	if got := firstTrue(5, func(int) bool { return immediate(&strat.StratJob{}) }); got != 0 {
+		t.Errorf("immediate activation fired at bar %d, want 0", got)
+	}
+
+	emas := [][3]float64{
+		{100, 101, 99},  // karışık
+		{101, 100, 100}, // orta = yavaş
+		{102, 101, 100}, // yukarı sıralı
+	}
+	if got := firstTrue(len(emas), func(i int) bool { return emasAligned(emas[i][0], emas[i][1], emas[i][2]) }); got != 2 {
+		t.Errorf("ema_aligned fired at bar %d, want 2", got)
+	}
+	if !emasAligned(98, 99, 100) {
+		t.Error("downward alignment must activate")
+	}
+
+	tests := []struct {
+		name   string
+		atrPct []float64
+		want   int
+	}{
+		{name: "three low bars", atrPct: []float64{2, 0.5, 0.6, 0.4, 0.3}, want: 3},
+		{name: "spike resets the count", atrPct: []float64{0.5, 0.5, 1.2, 0.5, 0.5, 0.5}, want: 5},
+		{name: "NaN resets the count", atrPct: []float64{0.5, 0.5, math.NaN(), 0.5, 0.5}, want: -1},
+		{name: "never low", atrPct: []float64{1, 1.5, 2}, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirm := &rangeConfirmation{maxATRPct: 1, needBars: 3}
+			if got := firstTrue(len(tt.atrPct), func(i int) bool { return confirm.update(tt.atrPct[i]) }); got != tt.want {
+				t.Errorf("range_confirmed fired at bar %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package dnm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/banbox/banbot/config"
+)
+
+// Parametre tipleri (JSON şema doğrulaması için)
+const (
+	paramBool   = "bool"
+	paramString = "string"
+	paramInt    = "int"
+	paramFloat  = "float"
+)
+
+// gridConfigSchema - GridPro parametre adları ve tipleri.
+// JSON config dosyasındaki alanlar bu adlarla birebir eşleşmelidir.
+var gridConfigSchema = map[string]string{
+	"enable_grid":             paramBool,
+	"grid_mode":               paramString,
+	"enable_regime_filter":    paramBool,
+	"regime_volatile_spacing": paramFloat,
+	"enable_entropy_filter":   paramBool,
+	"max_entropy":             paramFloat,
+	"activation_mode":         paramInt,
+	"activation_range_bars":   paramInt,
+	"activation_max_atr_pct":  paramFloat,
+	"instance_id":             paramString,
+	"mirror_symbol":           paramString,
+	"config_reload_secs":      paramInt,
+	"base_grid_count":         paramInt,
+	"base_spacing_pct":        paramFloat,
+	"live_respace":            paramFloat,
+	"auto_scale_grid_count":   paramBool,
+	"atr_period":              paramInt,
+	"atr_multiplier":          paramFloat,
+	"band_ema_period":         paramInt,
+	"level_cooldown_bars":     paramInt,
+	"max_fills_per_level":     paramInt,
+	"fft_auto_cooldown":       paramBool,
+	"fft_lookback":            paramInt,
+	"atr_spike_factor":        paramFloat,
+	"tick_size":               paramFloat,
+	"max_level_distance_pct":  paramFloat,
+	"neutral_zone_pct":        paramFloat,
+	"dynamic_spacing":         paramBool,
+	"dynamic_zone_atr":        paramFloat,
+	"require_close_confirm":   paramBool,
+	"inactivity_widen_bars":   paramInt,
+	"inactivity_widen_factor": paramFloat,
+
+	"trend_indicator":       paramInt,
+	"supertrend_period":     paramInt,
+	"supertrend_multiplier": paramFloat,
+	"enable_psar_exit":      paramBool,
+	"psar_step":             paramFloat,
+	"psar_max":              paramFloat,
+
+	"enable_ema_cross_rebalance": paramBool,
+	"ema_fast":                   paramInt,
+	"rebalance_cooldown_bars":    paramInt,
+	"max_drift_ratio":            paramFloat,
+	"fear_greed_value":           paramFloat,
+	"fear_greed_low":             paramFloat,
+	"fear_greed_high":            paramFloat,
+	"rebalance_interval_bars":    paramInt,
+
+	"max_rebalances_per_100_bars": paramInt,
+	"instability_cooldown_bars":   paramInt,
+
+	"enable_cci_filter": paramBool,
+	"cci_period":        paramInt,
+	"cci_overbought":    paramFloat,
+	"cci_oversold":      paramFloat,
+
+	"enable_delta_filter": paramBool,
+	"delta_lookback":      paramInt,
+
+	"enable_stoch_filter": paramBool,
+	"stoch_k":             paramInt,
+	"stoch_d":             paramInt,
+
+	"enable_signal_score": paramBool,
+	"min_buy_score":       paramFloat,
+	"min_sell_score":      paramFloat,
+
+	"enable_ichimoku_filter": paramBool,
+	"ichimoku_tenkan":        paramInt,
+	"ichimoku_kijun":         paramInt,
+	"ichimoku_senkou_b":      paramInt,
+
+	"enable_dc_filter": paramBool,
+	"dc_period":        paramInt,
+
+	"max_portfolio_risk":  paramFloat,
+	"max_single_position": paramFloat,
+	"min_alloc_pct":       paramFloat,
+	"dca_multiplier":      paramFloat,
+	"pyramid_mode":        paramInt,
+	"delta_hedge_mode":    paramInt,
+	"target_delta":        paramFloat,
+	"stop_loss_atr":       paramFloat,
+	"take_profit_atr":     paramFloat,
+	"tp_type":             paramInt,
+	"take_profit_pct":     paramFloat,
+	"max_hold_bars":       paramInt,
+	"tp_ratchet_pct":      paramFloat,
+	"enforce_symmetry":    paramBool,
+	"max_imbalance_pct":   paramFloat,
+
+	"enable_volume_confirmation": paramBool,
+	"volume_spike_factor":        paramFloat,
+	"enable_seasonality_filter":  paramBool,
+
+	"breakeven_on_first_tp":        paramBool,
+	"vis_output":                   paramString,
+	"vis_interval_bars":            paramInt,
+	"heatmap_bin_pct":              paramFloat,
+	"heatmap_path":                 paramString,
+	"heatmap_time_bin_bars":        paramInt,
+	"audit_log_path":               paramString,
+	"audit_flush_bars":             paramInt,
+	"redis_export":                 paramBool,
+	"redis_addr":                   paramString,
+	"persist_state":                paramBool,
+	"state_dir":                    paramString,
+	"enable_profiling":             paramBool,
+	"profile_log_interval":         paramInt,
+	"max_bar_duration_us":          paramInt,
+	"initial_capital":              paramFloat,
+	"max_order_retries":            paramInt,
+	"min_partial_fill_pct":         paramFloat,
+	"leverage":                     paramFloat,
+	"mm_rate":                      paramFloat,
+	"liquidation_safety_margin":    paramFloat,
+	"min_level_win_rate":           paramFloat,
+	"sharpe_window":                paramInt,
+	"mode_switch_sharpe_threshold": paramFloat,
+	"use_bbwidth_volatility":       paramBool,
+	"pnl_target_pct":               paramFloat,
+	"pnl_stop_pct":                 paramFloat,
+	"max_daily_loss_pct":           paramFloat,
+	"safe_mode_equity_pct":         paramFloat,
+	"auto_restart_sessions":        paramInt,
+	"max_init_retries":             paramInt,
+	"backfill_bars":                paramInt,
+	"track_correlation":            paramBool,
+	"funding_rate_pct":             paramFloat,
+	"funding_interval_bars":        paramInt,
+	"realloc_interval_bars":        paramInt,
+	"max_concurrent_trades":        paramInt,
+	"max_levels_per_bar":           paramInt,
+	"order_type":                   paramInt,
+	"limit_order_max_bars":         paramInt,
+	"expected_bars_per_level":      paramInt,
+	"slippage_pct":                 paramFloat,
+	"slippage_seed":                paramInt,
+	"sim_ticks_per_bar":            paramInt,
+	"exchange_a":                   paramString,
+	"exchange_b":                   paramString,
+	"arb_threshold_pct":            paramFloat,
+	"arb_exit_pct":                 paramFloat,
+	"arb_levels":                   paramInt,
+	"transfer_cost_pct":            paramFloat,
+	"sim_spread_pct":               paramFloat,
+	"sim_spread_period":            paramInt,
+	"spread_pct":                   paramFloat,
+	"fee_pct":                      paramFloat,
+
+	"reinvest_pnl":                  paramBool,
+	"reinvest_pct":                  paramFloat,
+	"max_capital_growth_multiplier": paramFloat,
+
+	"macro_spacing_atr": paramFloat,
+	"macro_levels":      paramInt,
+	"micro_spacing_atr": paramFloat,
+	"micro_levels":      paramInt,
+	"use_stochrsi":      paramBool,
+
+	"enable_night_mode":        paramBool,
+	"night_start_hour":         paramInt,
+	"night_end_hour":           paramInt,
+	"night_spacing_multiplier": paramFloat,
+}
+
+// appliedConfigs - NewGridStrategy'nin config dosyasını zaten uyguladığı pol'ler; strateji kurucusu
+// dosyayı ikinci kez okumaz
+var appliedConfigs sync.Map
+
+// markConfigApplied - pol için config dosyası uygulandı
+func markConfigApplied(pol *config.RunPolicyConfig) {
+	appliedConfigs.Store(pol, true)
+}
+
+// takeConfigApplied - pol'ün config dosyası uygulandıysa true döner ve işareti kaldırır
+func takeConfigApplied(pol *config.RunPolicyConfig) bool {
+	_, ok := appliedConfigs.LoadAndDelete(pol)
+	return ok
+}
+
+// loadGridConfigFromFile - JSON dosyasındaki her alan için pol.Def çağırır.
+// Böylece GridPro içindeki pol.Def çağrıları dosyadaki değeri döndürür,
+// dosyada olmayan alanlar programatik varsayılanlara düşer.
+func loadGridConfigFromFile(path string, pol *config.RunPolicyConfig) error {
+	values, err := readGridConfig(path)
+	if err != nil {
+		return err
+	}
+	for name, value := range values {
+		pol.Def(name, value)
+	}
+	return nil
+}
+
+// readGridConfig - dosyayı okur ve doğrular; int alanlar int'e çevrilmiş olarak döner
+func readGridConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read grid config: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse grid config %s: %w", path, err)
+	}
+	if err := validateGridConfig(raw); err != nil {
+		return nil, fmt.Errorf("invalid grid config %s: %w", path, err)
+	}
+	for name, value := range raw {
+		if gridConfigSchema[name] == paramInt {
+			raw[name] = int(value.(float64))
+		}
+	}
+	return raw, nil
+}
+
+// validateGridConfig - bilinmeyen alanları ve tip uyumsuzluklarını reddeder
+func validateGridConfig(raw map[string]interface{}) error {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kind, ok := gridConfigSchema[name]
+		if !ok {
+			return fmt.Errorf("unknown parameter %q", name)
+		}
+		value := raw[name]
+		switch kind {
+		case paramBool:
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("parameter %q must be a bool", name)
+			}
+		case paramString:
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("parameter %q must be a string", name)
+			}
+		case paramInt:
+			num, ok := value.(float64)
+			if !ok || num != float64(int(num)) {
+				return fmt.Errorf("parameter %q must be an integer", name)
+			}
+		case paramFloat:
+			if _, ok := value.(float64); !ok {
+				return fmt.Errorf("parameter %q must be a number", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package dnm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/banbox/banbot/config"
+)
+
+// fullGridConfig - şemadaki her parametre için tipine uygun bir değer
+func fullGridConfig() map[string]interface{} {
+	values := make(map[string]interface{}, len(gridConfigSchema))
+	for name, kind := range gridConfigSchema {
+		switch kind {
+		case paramBool:
+			values[name] = true
+		case paramString:
+			values[name] = name + "_value"
+		case paramInt:
+			values[name] = 7
+		case paramFloat:
+			values[name] = 1.25
+		}
+	}
+	return values
+}
+
+func writeGridConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "grid.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGridConfigFileRoundTrip(t *testing.T) {
+	want := fullGridConfig()
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeGridConfig(t, string(data))
+
+	got, err := readGridConfig(path)
+	if err != nil {
+		t.Fatalf("readGridConfig: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d parameters, want %d", len(got), len(want))
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %#v (%T), want %#v (%T)", name, got[name], got[name], value, value)
+		}
+	}
+	if err := loadGridConfigFromFile(path, &config.RunPolicyConfig{}); err != nil {
+		t.Errorf("loadGridConfigFromFile: %v", err)
+	}
+}
+
+func TestReadGridConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "malformed json", content: `{"enable_grid": }`},
+		{name: "unknown parameter", content: `{"grid_count": 5}`},
+		{name: "wrong type", content: `{"base_grid_count": "5"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readGridConfig(writeGridConfig(t, tt.content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := readGridConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// configFileMeta - config dosyasının kendisini seçen parametre; dosyada yer almaz
+const configFileMeta = "config_file"
+
+// Paketteki her pol.Def adı şemada bulunmalı; aksi halde o parametreyi ayarlayan dosya reddedilir
+func TestGridConfigSchemaCoversDefs(t *testing.T) {
+	defName := regexp.MustCompile(`\bpol\.Def\(\s*"([a-z0-9_]+)"`)
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range defName.FindAllStringSubmatch(string(data), -1) {
+			name := match[1]
+			found++
+			if name == configFileMeta {
+				continue
+			}
+			if _, ok := gridConfigSchema[name]; !ok {
+				t.Errorf("%s: parameter %q missing from gridConfigSchema", file, name)
+			}
+		}
+	}
+	if found == 0 {
+		t.Fatal("no pol.Def calls found")
+	}
+}
+
+// Factory'nin uyguladığı config dosyası strateji kurucusunda yalnızca bir kez atlanır
+func TestConfigAppliedMark(t *testing.T) {
+	pol, other := &config.RunPolicyConfig{}, &config.RunPolicyConfig{}
+	if takeConfigApplied(pol) {
+		t.Fatal("unmarked policy reported as applied")
+	}
+	markConfigApplied(pol)
+	if takeConfigApplied(other) {
+		t.Error("mark leaked to another policy")
+	}
+	if !takeConfigApplied(pol) {
+		t.Error("marked policy not reported as applied")
+	}
+	if takeConfigApplied(pol) {
+		t.Error("mark not cleared after take")
+	}
+}
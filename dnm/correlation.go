@@ -0,0 +1,106 @@
+package dnm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// correlationWindow - sembol başına tutulan en fazla bar PnL sayısı
+const correlationWindow = 500
+
+// gridPnLStore - paralel instance'ların bar bazlı PnL serileri (sembol -> *pnlHistory)
+var gridPnLStore sync.Map
+
+type pnlHistory struct {
+	mu     sync.Mutex
+	values []float64
+}
+
+func (h *pnlHistory) snapshot() []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]float64(nil), h.values...)
+}
+
+// updateCorrelationMatrix - sembolün bu bardaki PnL'ini ortak store'a ekler
+func updateCorrelationMatrix(symbol string, pnl float64, store *sync.Map) {
+	val, _ := store.LoadOrStore(symbol, &pnlHistory{})
+	hist := val.(*pnlHistory)
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	hist.values = append(hist.values, pnl)
+	if len(hist.values) > correlationWindow {
+		hist.values = hist.values[len(hist.values)-correlationWindow:]
+	}
+}
+
+// computeMatrix - tüm semboller arasında Pearson korelasyon matrisi.
+// Seriler farklı uzunluktaysa son ortak bar sayısı kullanılır.
+func computeMatrix(store *sync.Map) map[string]map[string]float64 {
+	series := make(map[string][]float64)
+	store.Range(func(key, val any) bool {
+		series[key.(string)] = val.(*pnlHistory).snapshot()
+		return true
+	})
+	res := make(map[string]map[string]float64, len(series))
+	for a, xs := range series {
+		res[a] = make(map[string]float64, len(series))
+		for b, ys := range series {
+			n := min(len(xs), len(ys))
+			res[a][b] = pearson(xs[len(xs)-n:], ys[len(ys)-n:])
+		}
+	}
+	return res
+}
+
+// pearson - iki serinin korelasyonu; varyansı sıfır olan seride NaN
+func pearson(xs, ys []float64) float64 {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return math.NaN()
+	}
+	mx, sx := meanStd(xs)
+	my, sy := meanStd(ys)
+	if sx == 0 || sy == 0 {
+		return math.NaN()
+	}
+	cov := 0.0
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+	}
+	cov /= float64(len(xs) - 1)
+	return cov / (sx * sy)
+}
+
+// isCorrelationLeader - matrisi hesaplayıp loglayan instance: alfabetik olarak ilk sembol
+func isCorrelationLeader(symbol string, store *sync.Map) bool {
+	leader := true
+	store.Range(func(key, _ any) bool {
+		if key.(string) < symbol {
+			leader = false
+			return false
+		}
+		return true
+	})
+	return leader
+}
+
+// formatMatrix - matrisi log için sembol sırasıyla tablo haline getirir
+func formatMatrix(matrix map[string]map[string]float64) string {
+	symbols := make([]string, 0, len(matrix))
+	for symbol := range matrix {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	var b strings.Builder
+	for _, a := range symbols {
+		b.WriteString(a)
+		for _, c := range symbols {
+			fmt.Fprintf(&b, " %6.2f", matrix[a][c])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
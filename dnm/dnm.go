@@ -1,8 +1,10 @@
 package dnm
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"math"
+	"math/rand"
 	"time"
 
 	"github.com/banbox/banbot/config"
@@ -11,47 +13,345 @@ import (
 	ta "github.com/banbox/banta"
 )
 
-// Sadece grid_pro stratejisini ekle, mevcut fonksiyonları değiştirme
+// Sadece grid stratejilerini ekle, mevcut fonksiyonları değiştirme
 func init() {
-	// Mevcut map'e sadece grid_pro'yu ekle
+	inGridPro := make(map[string]bool, len(gridProGroup))
+	gridPro := make(map[string]strat.FuncMakeStrat, len(gridProGroup))
+	for name, gridType := range gridProGroup {
+		inGridPro[gridType] = true
+		gridPro[name] = makeGridStrategy(gridType)
+	}
+	strat.AddStratGroup("gridpro", gridPro)
+	
+	// Mevcut map'e grid stratejilerini NewGridStrategy üzerinden ekle
 	if existingMap := strat.GetStratGroup("ma"); existingMap != nil {
-		existingMap["grid_pro"] = GridPro
+		for name := range gridStrategies {
+			if !inGridPro[name] {
+				existingMap[name] = makeGridStrategy(name)
+			}
+		}
 	}
 }
 
 // GridPro - Professional Grid Trading System
 func GridPro(pol *config.RunPolicyConfig) *strat.TradeStrat {
+	return newGridPro(pol, nil, GridConfig{})
+}
+
+// GridProWithConfig - hook'ları cfg'den alan GridPro
+func GridProWithConfig(pol *config.RunPolicyConfig, cfg GridConfig) *strat.TradeStrat {
+	return newGridPro(pol, nil, cfg)
+}
+
+// newGridPro - activation nil değilse grid, activation true dönene kadar kurulmaz
+func newGridPro(pol *config.RunPolicyConfig, activation func(s *strat.StratJob) bool, cfg GridConfig) *strat.TradeStrat {
+	
+	// JSON config dosyası (opsiyonel) - dosyadaki alanlar aşağıdaki varsayılanları ezer.
+	// NewGridStrategy dosyayı zaten uyguladıysa tekrar okunmaz.
+	configFile := string(pol.Def("config_file", ""))
+	if configFile != "" && !takeConfigApplied(pol) {
+		if err := loadGridConfigFromFile(configFile, pol); err != nil {
+			log.Printf("grid_pro: config file ignored: %v", err)
+		}
+	}
 	
 	// Pine Script parametreleri
 	enableGrid := bool(pol.Def("enable_grid", true))
 	gridMode := string(pol.Def("grid_mode", "Fixed Spacing"))
+	enableRegimeFilter := bool(pol.Def("enable_regime_filter", false)) // trendde dur, yatayda sabit aralık, volatilde genişlet
+	enableEntropyFilter := bool(pol.Def("enable_entropy_filter", false)) // getiri ApEn'i yüksekse (gürültü) emir açma
+	maxEntropy := float64(pol.Def("max_entropy", 2.0, core.PNorm(0.5, 2.5)))
+	regimeVolatileSpacing := float64(pol.Def("regime_volatile_spacing", 1.5, core.PNorm(1.0, 3.0)))
+	instanceID := string(pol.Def("instance_id", "0")) // aynı sembolde birden fazla instance için tag öneki
+	mirrorSymbol := string(pol.Def("mirror_symbol", ""))  // eş sembolde ters yönlü grid (pairs trading)
 	baseGridCount := int(pol.Def("base_grid_count", 8, core.PNorm(3, 15)))
 	baseSpacingPct := float64(pol.Def("base_spacing_pct", 1.0, core.PNorm(0.2, 3.0)))
+	autoScaleGridCount := bool(pol.Def("auto_scale_grid_count", false)) // kurulumda 30 günlük aralığa göre
 	atrPeriod := int(pol.Def("atr_period", 14, core.PNorm(5, 50)))
 	atrMultiplier := float64(pol.Def("atr_multiplier", 1.5, core.PNorm(0.5, 3.0)))
+	bandEMAPeriod := int(pol.Def("band_ema_period", 20, core.PNorm(10, 50))) // ATR Band modu
+	levelCooldownBars := int(pol.Def("level_cooldown_bars", 5, core.PNorm(1, 20))) // ATR Band modunda seviye tekrar tetiklenme beklemesi
+	maxFillsPerLevel := int(pol.Def("max_fills_per_level", 1, core.PNorm(1, 5))) // >1 ise seviye level_cooldown_bars arayla tekrar dolabilir (DCA)
+	fftAutoCooldown := bool(pol.Def("fft_auto_cooldown", false)) // level_cooldown_bars = baskın salınım periyodu / 2
+	fftLookback := int(pol.Def("fft_lookback", 128, core.PNorm(32, 512)))
+	atrSpikeFactor := float64(pol.Def("atr_spike_factor", 2.0, core.PNorm(1.5, 4.0)))
+	tickSize := float64(pol.Def("tick_size", 0.0001))
+	maxLevelDistancePct := float64(pol.Def("max_level_distance_pct", 10.0, core.PNorm(3.0, 30.0)))
+	neutralZonePct := float64(pol.Def("neutral_zone_pct", 0.0, core.PNorm(0.0, 2.0)))
+	dynamicSpacing := bool(pol.Def("dynamic_spacing", false))
+	dynamicZoneATR := float64(pol.Def("dynamic_zone_atr", 0.1, core.PNorm(0.05, 0.5)))
+	requireCloseConfirm := bool(pol.Def("require_close_confirm", false)) // seviye kapanışla geçilmeli
+	inactivityWidenBars := int(pol.Def("inactivity_widen_bars", 100, core.PNorm(20, 500)))
+	inactivityWidenFactor := float64(pol.Def("inactivity_widen_factor", 1.2, core.PNorm(1.05, 2.0)))
+	
+	// Trend filter: 1=EMA, 2=Supertrend
+	trendIndicator := int(pol.Def("trend_indicator", TrendIndicatorEMA))
+	supertrendPeriod := int(pol.Def("supertrend_period", 10, core.PNorm(5, 30)))
+	supertrendMultiplier := float64(pol.Def("supertrend_multiplier", 3.0, core.PNorm(1.0, 5.0)))
+	enablePsarExit := bool(pol.Def("enable_psar_exit", false)) // PSAR dönünce ters yöndeki pozisyonları kapat
+	psarStep := float64(pol.Def("psar_step", 0.02, core.PNorm(0.01, 0.05)))
+	psarMax := float64(pol.Def("psar_max", 0.2, core.PNorm(0.1, 0.4)))
+	maxHoldBars := int(pol.Def("max_hold_bars", 0, core.PNorm(0, 500))) // 0 = süre sınırı yok
+	enableEMACrossRebalance := bool(pol.Def("enable_ema_cross_rebalance", false))
+	emaFast := int(pol.Def("ema_fast", 9, core.PNorm(5, 20))) // yavaş EMA trend EMA'sıdır (50)
+	rebalanceCooldownBars := int(pol.Def("rebalance_cooldown_bars", 20, core.PNorm(5, 100)))
+	fearGreedValue := float64(pol.Def("fear_greed_value", 50.0)) // dışarıdan güncellenir (config reload)
+	fearGreedLow := float64(pol.Def("fear_greed_low", 25.0, core.PNorm(10.0, 40.0)))
+	fearGreedHigh := float64(pol.Def("fear_greed_high", 75.0, core.PNorm(60.0, 90.0)))
+	maxDriftRatio := float64(pol.Def("max_drift_ratio", 0.75, core.PNorm(0.6, 0.95)))
+	autoBalanceBars := int(pol.Def("rebalance_interval_bars", 200)) // 0 = long/short boyut dengelemesi kapalı
+	maxRebalancesPer100 := int(pol.Def("max_rebalances_per_100_bars", 5, core.PNorm(2, 20)))
+	instabilityCooldownBars := int(pol.Def("instability_cooldown_bars", 200, core.PNorm(50, 1000)))
+	
+	// CCI overbought/oversold filter
+	enableCCIFilter := bool(pol.Def("enable_cci_filter", false))
+	cciPeriod := int(pol.Def("cci_period", 20, core.PNorm(10, 50)))
+	cciOverbought := float64(pol.Def("cci_overbought", 100.0))
+	cciOversold := float64(pol.Def("cci_oversold", -100.0))
+	
+	// Cumulative delta (order flow) filter
+	enableDeltaFilter := bool(pol.Def("enable_delta_filter", false))
+	deltaLookback := int(pol.Def("delta_lookback", 20, core.PNorm(5, 100)))
+	
+	// Stochastic crossover filter
+	enableStochFilter := bool(pol.Def("enable_stoch_filter", false))
+	stochK := int(pol.Def("stoch_k", 5, core.PNorm(3, 21)))
+	stochD := int(pol.Def("stoch_d", 3, core.PNorm(2, 9)))
+	enableSignalScore := bool(pol.Def("enable_signal_score", false)) // RSI/CCI/Stoch/trend/rejim skorlarının ağırlıklı ortalaması
+	minBuyScore := float64(pol.Def("min_buy_score", 0.6, core.PNorm(0.3, 0.9)))
+	minSellScore := float64(pol.Def("min_sell_score", 0.6, core.PNorm(0.3, 0.9)))
+	
+	// Ichimoku cloud filter
+	enableIchimokuFilter := bool(pol.Def("enable_ichimoku_filter", false))
+	ichimokuTenkan := int(pol.Def("ichimoku_tenkan", 9))
+	ichimokuKijun := int(pol.Def("ichimoku_kijun", 26))
+	ichimokuSenkouB := int(pol.Def("ichimoku_senkou_b", 52))
+	
+	// Donchian kanalı: kırılım sırasında grid kurma
+	enableDCFilter := bool(pol.Def("enable_dc_filter", true))
+	dcPeriod := int(pol.Def("dc_period", 20, core.PNorm(10, 60)))
 	
 	// Risk Management
 	maxPortfolioRisk := float64(pol.Def("max_portfolio_risk", 15.0, core.PNorm(5.0, 30.0)))
 	maxSinglePosition := float64(pol.Def("max_single_position", 5.0, core.PNorm(1.0, 10.0)))
+	baseMaxSinglePosition := maxSinglePosition
+	minAllocPct := float64(pol.Def("min_alloc_pct", 5.0, core.PNorm(0.0, 20.0))) // Sharpe'a göre sermaye dağıtımında strateji başına taban
+	reallocIntervalBars := int(pol.Def("realloc_interval_bars", 500)) // 0 = instance'lar arası dağıtım kapalı
+	reinvestPnl := bool(pol.Def("reinvest_pnl", false)) // döngü tamamlanınca gerçekleşmiş PnL'i boyutlandırmaya kat
+	reinvestPct := float64(pol.Def("reinvest_pct", 50.0, core.PNorm(0.0, 100.0)))
+	maxCapitalGrowth := float64(pol.Def("max_capital_growth_multiplier", 3.0, core.PNorm(1.0, 10.0)))
+	dcaMultiplier := float64(pol.Def("dca_multiplier", 1.0, core.PNorm(1.0, 2.0))) // 1 = eşit boyut
+	pyramidMode := int(pol.Def("pyramid_mode", PyramidFlat)) // flat değilse dca_multiplier yerine geçer
+	deltaHedgeMode := int(pol.Def("delta_hedge_mode", DeltaHedgeOff))
+	targetDelta := float64(pol.Def("target_delta", 0.0)) // long - short miktar hedefi
 	stopLossATR := float64(pol.Def("stop_loss_atr", 2.0, core.PNorm(1.0, 5.0)))
 	takeProfitATR := float64(pol.Def("take_profit_atr", 3.0, core.PNorm(1.5, 8.0)))
+	tpType := int(pol.Def("tp_type", TPTypeATR)) // 1=ATR katı, 2=sonraki grid seviyesi, 3=sabit yüzde
+	takeProfitPct := float64(pol.Def("take_profit_pct", 1.0, core.PNorm(0.3, 5.0)))
+	tpRatchetPct := float64(pol.Def("tp_ratchet_pct", 0.1, core.PNorm(0.0, 0.5))) // dolan seviye başına TP artışı (yalnızca ATR TP)
+	enforceSymmetry := bool(pol.Def("enforce_symmetry", false))
+	enableVolumeConfirmation := bool(pol.Def("enable_volume_confirmation", false))
+	volumeSpikeFactor := float64(pol.Def("volume_spike_factor", 1.5, core.PNorm(1.0, 3.0)))
+	enableSeasonalityFilter := bool(pol.Def("enable_seasonality_filter", false))
+	maxImbalancePct := float64(pol.Def("max_imbalance_pct", 2.0, core.PNorm(0.5, 10.0)))
+	breakevenOnFirstTP := bool(pol.Def("breakeven_on_first_tp", false))
+	initialCapital := float64(pol.Def("initial_capital", 10000.0))
+	maxOrderRetries := int(pol.Def("max_order_retries", 1))
+	minPartialFillPct := float64(pol.Def("min_partial_fill_pct", 50.0, core.PNorm(10.0, 90.0)))
+	
+	// Kaldıraçlı grid'de tasfiye fiyatı korunması (leverage 1 = kapalı)
+	leverage := float64(pol.Def("leverage", 1.0, core.PNorm(1.0, 20.0)))
+	mmRate := float64(pol.Def("mm_rate", 0.005))
+	liquidationSafetyMargin := float64(pol.Def("liquidation_safety_margin", 0.3, core.PNorm(0.1, 0.5)))
+	minLevelWinRate := float64(pol.Def("min_level_win_rate", 0.4, core.PNorm(0.2, 0.6)))
+	sharpeWindow := int(pol.Def("sharpe_window", 100))
+	modeSwitchSharpe := float64(pol.Def("mode_switch_sharpe_threshold", 0.5))
+	useBBWidthVolatility := bool(pol.Def("use_bbwidth_volatility", false)) // mod seçiminde ATR yerine Bollinger genişliği
+	pnlTargetPct := float64(pol.Def("pnl_target_pct", 0.0))
+	pnlStopPct := float64(pol.Def("pnl_stop_pct", 0.0))
+	safeModeEquityPct := float64(pol.Def("safe_mode_equity_pct", 70.0, core.PNorm(50.0, 95.0))) // equity zirvenin bu %'sinin altında safe mode
+	maxDailyLossPct := float64(pol.Def("max_daily_loss_pct", 5.0, core.PNorm(1.0, 20.0))) // session içi, 0 = kapalı
+	autoRestartSessions := int(pol.Def("auto_restart_sessions", 1))
+	maxInitRetries := int(pol.Def("max_init_retries", 5))
+	backfillBars := int(pol.Def("backfill_bars", 0)) // > 0 ise grid kurulunca önceki barlarda sentetik dolum simülasyonu
+	trackCorrelation := bool(pol.Def("track_correlation", false))
+	fundingRatePct := float64(pol.Def("funding_rate_pct", 0.01))   // saatlik
+	fundingIntervalBars := int(pol.Def("funding_interval_bars", 0)) // 0 = spot, funding yok
+	maxConcurrentTrades := int(pol.Def("max_concurrent_trades", 16, core.PNorm(4, 30)))
+	maxLevelsPerBar := int(pol.Def("max_levels_per_bar", 2, core.PNorm(1, 8))) // gap barlarında kalanlar sonraki bara kalır
+	orderType := int(pol.Def("order_type", OrderTypeMarket)) // 1=market, 2=limit
+	limitOrderMaxBars := int(pol.Def("limit_order_max_bars", 20))
+	
+	// Backtest slippage simülasyonu (sabit seed ile tekrarlanabilir)
+	slippagePct := float64(pol.Def("slippage_pct", 0.0, core.PNorm(0.0, 0.5)))
+	slippageSeed := int(pol.Def("slippage_seed", 42))
+	
+	// İşlem maliyeti: spread ve komisyon grid kârından düşer
+	spreadPct := float64(pol.Def("spread_pct", 0.0, core.PNorm(0.0, 1.0)))
+	feePct := float64(pol.Def("fee_pct", 0.0, core.PNorm(0.0, 0.2))) // tek yön komisyon
+	var slip func(price float64) float64
+	if core.BacktestMode && slippagePct > 0 {
+		slipRng := rand.New(rand.NewSource(int64(slippageSeed)))
+		slip = func(price float64) float64 {
+			return applySlippage(price, slippagePct, slipRng)
+		}
+	}
+	
+	// Gece modu: düşük likidite saatlerinde (UTC) daha geniş aralık, daha az işlem
+	enableNightMode := bool(pol.Def("enable_night_mode", false))
+	nightStartHour := int(pol.Def("night_start_hour", 22))
+	nightEndHour := int(pol.Def("night_end_hour", 6))
+	nightSpacingMultiplier := float64(pol.Def("night_spacing_multiplier", 2.0, core.PNorm(1.0, 4.0)))
+	
+	// Dashboard output (dosya yolu ya da unix://soket)
+	visOutput := string(pol.Def("vis_output", ""))
+	visIntervalBars := int(pol.Def("vis_interval_bars", 10))
+	heatmapBinPct := float64(pol.Def("heatmap_bin_pct", 0.5)) // 0 = kapalı
+	heatmapPath := string(pol.Def("heatmap_path", "")) // zaman×fiyat emir matrisi CSV yolu, boş = kapalı
+	heatmapTimeBinBars := int(pol.Def("heatmap_time_bin_bars", 100))
+	auditLogPath := string(pol.Def("audit_log_path", "")) // emir açılış/kapanış JSONL kaydı, boş = kapalı
+	auditFlushBars := int(pol.Def("audit_flush_bars", 100))
+	redisExport := bool(pol.Def("redis_export", false))
+	redisAddr := string(pol.Def("redis_addr", "localhost:6379"))
+	
+	// OnBar süre ölçümü
+	enableProfiling := bool(pol.Def("enable_profiling", false))
+	profileLogInterval := int(pol.Def("profile_log_interval", 500))
+	maxBarDurationUs := int(pol.Def("max_bar_duration_us", 1000))
+	
+	// Yeniden başlatmada grid durumunu geri yükle (state_dir boşsa GridConfig.Storage)
+	persistState := bool(pol.Def("persist_state", false))
+	stateDir := string(pol.Def("state_dir", ""))
+	storage := selectGridStorage(cfg, persistState, stateDir)
+	var stateLoaded bool = false
+	var exporter *RedisExporter
+	if redisExport {
+		exporter = NewRedisExporter(redisAddr)
+	}
+	
+	// Config dosyası değişikliklerini izle (0 = kapalı)
+	configReloadSecs := int(pol.Def("config_reload_secs", 0))
+	var stopConfigWatch func() = nil
+	if configFile != "" && configReloadSecs > 0 {
+		stopConfigWatch = watchConfigFile(configFile, pol, time.Duration(configReloadSecs)*time.Second)
+	}
+	live := liveConfigFor(pol)
+	var liveVersion uint64 = 0
+	
+	// Pozisyonları kapatmadan aralık değişikliği (fiyat cinsinden); bir kez uygulanır ve sıfırlanır
+	respaceTo := float64(pol.Def("live_respace", 0.0))
+	var spacingOverride float64 = 0
+	
+	// Seviye boyutlandırma
+	var levelSizing LevelSizingFunc = uniformSizing
+	if pyramidMode != PyramidFlat {
+		levelSizing = func(levelIndex int) float64 {
+			return pyramidSize(1.0, levelIndex, baseGridCount, pyramidMode) // auto-scale sonrası güncel sayı
+		}
+	} else if dcaMultiplier != 1.0 {
+		levelSizing = dcaSizing(dcaMultiplier)
+	}
 	
 	// Strategy state
 	var gridBasePrice float64 = 0
 	var gridInitialized bool = false
 	var totalGridTrades int = 0
-	var gridBuyLevels [8]float64
-	var gridSellLevels [8]float64
-	var gridLevelUsed [16]bool
+	var gridCount int = min(baseGridCount, maxGridLevels)
+	var skippedLevels int = 0
+	var peakEquity float64 = 0
+	var safeMode bool = false
+	var normalParams GridParams // safe mode'a girerken saklanan parametreler
+	currentParams := func() GridParams {
+		return GridParams{
+			BaseGridCount:            baseGridCount,
+			BaseSpacingPct:           baseSpacingPct,
+			MaxConcurrentTrades:      maxConcurrentTrades,
+			EnableRegimeFilter:       enableRegimeFilter,
+			EnableEntropyFilter:      enableEntropyFilter,
+			EnableCCIFilter:          enableCCIFilter,
+			EnableDeltaFilter:        enableDeltaFilter,
+			EnableStochFilter:        enableStochFilter,
+			EnableIchimokuFilter:     enableIchimokuFilter,
+			EnableDCFilter:           enableDCFilter,
+			EnableVolumeConfirmation: enableVolumeConfirmation,
+			EnableSeasonalityFilter:  enableSeasonalityFilter,
+			EnableSignalScore:        enableSignalScore,
+		}
+	}
+	setParams := func(p GridParams) {
+		baseGridCount, baseSpacingPct, maxConcurrentTrades = p.BaseGridCount, p.BaseSpacingPct, p.MaxConcurrentTrades
+		enableRegimeFilter, enableEntropyFilter = p.EnableRegimeFilter, p.EnableEntropyFilter
+		enableCCIFilter, enableDeltaFilter, enableStochFilter = p.EnableCCIFilter, p.EnableDeltaFilter, p.EnableStochFilter
+		enableIchimokuFilter, enableDCFilter = p.EnableIchimokuFilter, p.EnableDCFilter
+		enableVolumeConfirmation, enableSeasonalityFilter = p.EnableVolumeConfirmation, p.EnableSeasonalityFilter
+		enableSignalScore = p.EnableSignalScore
+		gridCount = min(baseGridCount, maxGridLevels)
+	}
+	var barsSinceLastTrade int = 0
+	var spacingWiden float64 = 1.0 // işlem olmadıkça büyür, en fazla 3x
+	var totalRealizedPnl float64 = 0
+	var gridHalted bool = false
+	var haltedSessionCount int = 0
+	gridLevels := newGridLevelMap()
+	stats := &GridStats{}
+	returns := NewRollingReturns(sharpeWindow)
+	perf := &PerfTracker{}
+	session := newSessionTracker()
+	var sessionStartEquity float64 = 0
+	var dailyHaltActive bool = false
+	var lastBarTime int64 = 0
+	var lowSharpeBars int = 0
+	var barSecs int64 = 0
+	var nightMode bool = false
+	var lastSpacing float64 = 0
+	var previousATR float64 = 0
+	var prevFastEMA, prevSlowEMA float64 = 0, 0
+	var gridInitBarIndex int = 0
+	var lastRebalanceBarIndex int = 0
+	var rebalanceTimes []int // son 100 bardaki init/rebalance barları
+	var gridUnstable bool = false
+	var unstableUntil int = 0
+	var longRatio, shortRatio float64 = 1, 1
+	var ratchetBuyMult, ratchetSellMult float64 = 1, 1
+	capitalBase := initialCapital
+	var prevBands []float64
+	var prevStochK, prevStochD float64 = math.NaN(), math.NaN()
+	var initRetries int = 0
+	pendingLimitOrders := make(map[string]pendingLimit) // seviye adı -> dolmamış limit emir
+	var seasonality [7][24]float64 // UTC gün/saat bazında ortalama bar getirisi
+	var seasonalityCounts [7][24]int
+	var seasonalityStart int64 = 0
+	var activityMap *HeatmapExporter
+	var backfillDone bool = false
+	var auditLog *GridOrderAuditLog
+	if auditLogPath != "" {
+		auditLog = NewGridOrderAuditLog(auditLogPath, instanceID)
+	}
 	
 	return &strat.TradeStrat{
-		WarmupNum: 100,
+		WarmupNum:     gridWarmupNum,
+		StopEnterBars: validateStopEnterBars(pol),
 		
 		OnBar: func(s *strat.StratJob) {
 			e := s.Env
+			if enableProfiling {
+				start := time.Now()
+				defer func() {
+					perf.Record(time.Since(start))
+					avg := perf.Average()
+					stats.AvgBarDurationNs = avg.Nanoseconds()
+					if profileLogInterval > 0 && e.BarIndex%profileLogInterval == 0 {
+						s.Infof("Grid OnBar profile: avg %v over %d bars", avg, perf.Count())
+						if avg > time.Duration(maxBarDurationUs)*time.Microsecond {
+							s.Infof("Warning: grid OnBar average %v exceeds %dus", avg, maxBarDurationUs)
+						}
+					}
+				}()
+			}
 			
-			// Yeterli veri kontrolü
-			if e.Close.Len() < atrPeriod {
+			// Yeterli veri kontrolü (High/Low, Close ile aynı uzunlukta olmayabilir)
+			if e.Close.Len() < atrPeriod || e.High.Len() < atrPeriod || e.Low.Len() < atrPeriod {
+				stats.Skips.TooFewBars++
 				return
 			}
 			
@@ -59,80 +359,871 @@ func GridPro(pol *config.RunPolicyConfig) *strat.TradeStrat {
 			currentHigh := e.High.Last(0)
 			currentLow := e.Low.Last(0)
 			
+			if storage != nil && !stateLoaded {
+				stateLoaded = true
+				snap, err := storage.Load(gridStateKey(s.Symbol, s.TimeFrame, instanceID))
+				if err == nil {
+					if snap.Version < CurrentStateVersion {
+						s.Infof("Migrating state from version %d to %d", snap.Version, CurrentStateVersion)
+						snap = migrateSnapshot(snap)
+					}
+					gridBasePrice = snap.BasePrice
+					gridInitialized = snap.Initialized
+					gridHalted = snap.Halted
+					totalRealizedPnl = snap.RealizedPnl
+					gridLevels.Replace(snap.Levels)
+					gridInitBarIndex = e.BarIndex
+					lastRebalanceBarIndex = e.BarIndex
+					s.Infof("Grid state restored: base %.4f, %d levels, PnL %.2f", gridBasePrice, gridLevels.Len(), totalRealizedPnl)
+				} else if !errors.Is(err, ErrGridStateNotFound) {
+					s.Infof("Grid state load failed: %v", err)
+				}
+			}
+			
+			// Config dosyası değiştiyse spacing, seviye ve filtre parametrelerini yenile
+			if values, version, ok := live.since(liveVersion); ok {
+				liveVersion = version
+				prevMode, prevCount := gridMode, gridCount
+				setLiveParam(values, "grid_mode", &gridMode)
+				setLiveParam(values, "base_spacing_pct", &baseSpacingPct)
+				setLiveParam(values, "atr_multiplier", &atrMultiplier)
+				setLiveParam(values, "dynamic_spacing", &dynamicSpacing)
+				setLiveParam(values, "dynamic_zone_atr", &dynamicZoneATR)
+				setLiveParam(values, "enable_cci_filter", &enableCCIFilter)
+				setLiveParam(values, "cci_overbought", &cciOverbought)
+				setLiveParam(values, "cci_oversold", &cciOversold)
+				setLiveParam(values, "enable_ichimoku_filter", &enableIchimokuFilter)
+				setLiveParam(values, "stop_loss_atr", &stopLossATR)
+				setLiveParam(values, "take_profit_atr", &takeProfitATR)
+				setLiveParam(values, "fear_greed_value", &fearGreedValue)
+				if setLiveParam(values, "live_respace", &respaceTo) {
+					live.consume("live_respace") // sonraki yüklemelerde tekrar uygulanmasın
+				}
+				if setLiveParam(values, "base_grid_count", &baseGridCount) {
+					gridCount = min(baseGridCount, maxGridLevels)
+				}
+				if (gridMode != prevMode || gridCount != prevCount) && (gridMode == GridModeEvenOdd || prevMode == GridModeEvenOdd) {
+					clearPendingLevels(gridLevels) // seviye düzeni değişti
+				} else if gridCount != prevCount {
+					trimGridLevels(gridLevels, gridCount)
+				}
+				s.Infof("Grid config reloaded (version %d)", liveVersion)
+			}
+			
+			// Bar süresi (Sharpe yıllıklandırması için)
+			if lastBarTime > 0 && e.BarTime > lastBarTime {
+				barSecs = e.BarTime - lastBarTime
+			}
+			newSession := lastBarTime > 0 && isNewSession(lastBarTime, e.BarTime)
+			lastBarTime = e.BarTime
+			registerGridStats(s, stats)
+			
+			// Günlük özet: biten session'ın KPI'ları
+			if newSession {
+				kpi := session.computeSessionKPI(gridLevels.Snapshot())
+				s.Infof("Grid Session KPI: Trades=%d, WinRate=%.1f%%, PnL=%.2f, MaxDD=%.2f, AvgHold=%.1f bars, Utilization=%.1f%%",
+					kpi.SessionTrades, kpi.SessionWinRate*100, kpi.SessionPnL, kpi.SessionMaxDrawdown,
+					kpi.AvgHoldBars, kpi.GridUtilization*100)
+				session.reset()
+			}
+			
+			// Günlük zarar limiti: aşılırsa session sonuna kadar yeni emir açılmaz, açık emirler yönetilmeye devam eder
+			currentEquity := initialCapital + totalRealizedPnl + unrealizedPnL(GridOpenOrders(s, gridTagPrefix(instanceID)), currentPrice)
+			if newSession || sessionStartEquity == 0 {
+				sessionStartEquity = currentEquity
+				if dailyHaltActive {
+					dailyHaltActive = false
+					s.Infof("Grid daily loss halt lifted for new session")
+				}
+			}
+			if !dailyHaltActive && checkDailyLoss(currentEquity, sessionStartEquity, maxDailyLossPct) {
+				dailyHaltActive = true
+				s.Infof("Grid daily loss limit hit: equity %.2f vs session start %.2f, new orders halted", currentEquity, sessionStartEquity)
+			}
+			
+			// Safe mode: equity zirveden fazla düştüyse daha az seviye, daha geniş aralık, yarı işlem limiti
+			peakEquity = math.Max(peakEquity, currentEquity)
+			if active := safeModeTransition(safeMode, currentEquity, peakEquity, safeModeEquityPct); active != safeMode {
+				safeMode = active
+				if safeMode {
+					normalParams = currentParams()
+					params := normalParams
+					applySafeMode(&params)
+					setParams(params)
+					trimGridLevels(gridLevels, gridCount)
+					s.Infof("Grid safe mode ON: equity %.2f below %.0f%% of peak %.2f (levels %d, spacing %.2f%%, max trades %d)",
+						currentEquity, safeModeEquityPct, peakEquity, baseGridCount, baseSpacingPct, maxConcurrentTrades)
+				} else {
+					setParams(normalParams)
+					s.Infof("Grid safe mode OFF: equity %.2f recovered to %.0f%% of peak %.2f", currentEquity, safeModeExitPct(safeModeEquityPct), peakEquity)
+				}
+			}
+			
+			// Fiyat bin haritası - bin genişliği ilk fiyata göre sabitlenir
+			if heatmapBinPct > 0 {
+				if stats.HeatMap == nil {
+					stats.HeatMap = NewPriceBinMap(currentPrice * heatmapBinPct / 100)
+				}
+				if heatmapPath != "" && activityMap == nil {
+					activityMap = NewHeatmapExporter(currentPrice*(1-heatmapRangePct/100), currentPrice*(1+heatmapRangePct/100),
+						currentPrice*heatmapBinPct/100, heatmapTimeBinBars)
+				}
+				stats.HeatMap.Record(currentPrice)
+			}
+			
 			// Technical indicators
 			atrValue := ta.ATR(e.High, e.Low, e.Close, atrPeriod)
 			trendMA := ta.EMA(e.Close, 50)
+			if math.IsNaN(atrValue) || math.IsNaN(trendMA) || trendMA == 0 {
+				stats.Skips.NaNIndicators++
+				return
+			}
 			isUptrend := currentPrice > trendMA
+			trendStrength := (currentPrice - trendMA) / trendMA * 100
+			if trendIndicator == TrendIndicatorSupertrend {
+				stUp, stLine := computeSupertrend(e.High, e.Low, e.Close, supertrendPeriod, supertrendMultiplier)
+				if !math.IsNaN(stLine) && stLine > 0 {
+					isUptrend = stUp
+					trendStrength = (currentPrice - stLine) / stLine * 100
+				}
+			}
+			
+			// Taraf bazlı filtreler
+			var buyRestrictions, sellRestrictions Restriction
+			if dailyHaltActive {
+				buyRestrictions |= RestrictionDailyLoss
+				sellRestrictions |= RestrictionDailyLoss
+			}
+			if enableCCIFilter {
+				cciValue := ta.CCI(e.High, e.Low, e.Close, cciPeriod)
+				buyR, sellR := cciRestrictions(cciValue, cciOverbought, cciOversold)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+			}
+			if enableDeltaFilter {
+				delta := computeCumulativeDelta(e.Close, e.Open, e.High, e.Low, e.Volume, deltaLookback)
+				buyR, sellR := deltaRestrictions(delta)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+			}
+			if enableStochFilter {
+				k, d := computeStochastic(e.High, e.Low, e.Close, stochK, stochD)
+				buyR, sellR := stochRestrictions(k, d, prevStochK, prevStochD)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+				prevStochK, prevStochD = k, d
+			}
+			if enableIchimokuFilter {
+				spanA, spanB := computeIchimokuCloud(e.High, e.Low, ichimokuTenkan, ichimokuKijun, ichimokuSenkouB)
+				buyR, sellR := ichimokuRestrictions(currentPrice, spanA, spanB)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+			}
+			if enforceSymmetry {
+				buyR, sellR := symmetryRestrictions(computeExposureImbalance(s, instanceID), initialCapital, maxImbalancePct)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+			}
+			if enableVolumeConfirmation {
+				if r := volumeRestriction(e.Volume.Last(0), ta.SMA(e.Volume, 20), volumeSpikeFactor); r != 0 {
+					buyRestrictions |= r
+					sellRestrictions |= r
+					stats.Skips.LowVolume++
+				}
+			}
+			if enableSeasonalityFilter {
+				if seasonalityStart == 0 {
+					seasonalityStart = e.BarTime
+				}
+				if prevClose := e.Close.Last(1); prevClose > 0 {
+					updateSeasonalityGrid(&seasonality, &seasonalityCounts, e.BarTime, (currentPrice-prevClose)/prevClose)
+				}
+				if e.BarTime-seasonalityStart >= seasonalityMinHistory &&
+					isBadSeasonality(seasonality, e.BarTime, seasonalityThreshold(seasonality, seasonalityCounts)) {
+					buyRestrictions |= RestrictionSeasonality
+					sellRestrictions |= RestrictionSeasonality
+				}
+			}
+			var initRestrictions Restriction
+			regime := RegimeRanging
+			if enableRegimeFilter {
+				hurst := hurstExponent(seriesWindow(e.Close, regimeHurstWindow))
+				if !math.IsNaN(hurst) {
+					regime = classifyRegime(atrValue/currentPrice*100, hurst, trendStrength)
+				}
+				if regime.String() != stats.CurrentRegime {
+					s.Infof("Market regime: %s -> %s (Hurst=%.2f)", stats.CurrentRegime, regime, hurst)
+					stats.CurrentRegime = regime.String()
+					if regime == RegimeRanging && gridMode == GridModeATR {
+						gridMode = GridModeFixed
+						s.Infof("Grid mode switch: %s -> %s (ranging regime)", GridModeATR, gridMode)
+					}
+				}
+				if regime == RegimeTrending {
+					buyRestrictions |= RestrictionTrendingRegime
+					sellRestrictions |= RestrictionTrendingRegime
+					initRestrictions |= RestrictionTrendingRegime
+				}
+			}
+			if enableEntropyFilter {
+				stats.CurrentEntropy = computeApproximateEntropy(e.Close, entropyEmbedding, entropyTolerance)
+				if stats.CurrentEntropy > maxEntropy {
+					buyRestrictions |= RestrictionHighEntropy
+					sellRestrictions |= RestrictionHighEntropy
+				}
+			}
+			if enableSignalScore {
+				stochValue, _ := computeStochastic(e.High, e.Low, e.Close, stochK, stochD)
+				signals := defaultGridSignals(ta.RSI(e.Close, 14), ta.CCI(e.High, e.Low, e.Close, cciPeriod),
+					stochValue, trendStrength, regime)
+				stats.TradeScore = aggregateSignals(signals)
+				buyR, sellR := scoreRestrictions(stats.TradeScore, minBuyScore, minSellScore)
+				buyRestrictions |= buyR
+				sellRestrictions |= sellR
+			}
+			if enableDCFilter {
+				dcUpper, dcLower := donchianChannel(e.High, e.Low, dcPeriod)
+				initRestrictions |= donchianRestriction(currentPrice, dcUpper, dcLower)
+			}
+			
+			// Yeni base: korku/açgözlülük endeksine göre güncel fiyattan yarım aralık kaydırılır
+			sentimentBase := func() float64 {
+				return adjustBaseForSentiment(currentPrice, lastSpacing, fearGreedValue, fearGreedLow, fearGreedHigh)
+			}
 			
-			// Grid initialization
-			if !gridInitialized && enableGrid {
-				gridBasePrice = currentPrice
+			// Rebalance sıklığı: 100 barda çok fazla init/rebalance parametrelerin piyasaya uymadığını gösterir
+			markRebalanced := func() {
+				lastRebalanceBarIndex = e.BarIndex
+				gridLevels.ResetLayout() // base taşındı, seviyeler yeni base etrafında yeniden dizilir
+				rebalanceTimes = append(rebalanceTimes, e.BarIndex)
+				for len(rebalanceTimes) > 0 && e.BarIndex-rebalanceTimes[0] >= 100 {
+					rebalanceTimes = rebalanceTimes[1:]
+				}
+				if isGridUnstable(rebalanceTimes, e.BarIndex, 100, maxRebalancesPer100) {
+					s.Infof("Grid parameters appear unstable: too frequent rebalancing (%d in 100 bars), pausing for %d bars",
+						len(rebalanceTimes), instabilityCooldownBars)
+					gridUnstable = true
+					unstableUntil = e.BarIndex + instabilityCooldownBars
+					rebalanceTimes = nil
+				}
+			}
+			if gridUnstable && e.BarIndex >= unstableUntil {
+				gridUnstable = false
+				s.Infof("Grid instability cooldown over, rebalancing allowed again")
+			}
+			
+			// Grid initialization - warmup bitmeden base fiyat belirlenmez (EMA/ATR henüz oturmadı)
+			warmedUp := gridWarmedUp(e.Close.Len())
+			activated := gridInitialized || activation == nil || activation(s) // koşul her bar değerlendirilir
+			canInit := !gridInitialized && !gridHalted && !gridUnstable && enableGrid && warmedUp && activated && initRestrictions == 0
+			if canInit {
+				canInit, initRetries = runPreGridCheck(s, cfg.PreGridCheck, initRetries, maxInitRetries)
+			}
+			if canInit {
+				if autoScaleGridCount {
+					lookback := e.Close.Len()
+					if barSecs > 0 {
+						lookback = int(30 * 86400 / barSecs)
+					}
+					if suggested := suggestGridCount(rangeRatio(e.High, e.Low, lookback), baseSpacingPct); suggested > 0 {
+						baseGridCount = suggested
+						gridCount = min(baseGridCount, maxGridLevels)
+						s.Infof("Grid count auto-scaled to %d (active %d) from 30-day range", baseGridCount, gridCount)
+					}
+				}
+				gridBasePrice = sentimentBase()
 				gridInitialized = true
+				gridInitBarIndex = e.BarIndex
+				markRebalanced()
+				initRetries = 0
 				s.Infof("Grid initialized at price: %.4f", gridBasePrice)
 			}
 			
 			// Grid spacing calculation
 			var spacing float64
 			switch gridMode {
-			case "Fixed Spacing":
+			case "Fixed Spacing", GridModeEvenOdd:
 				spacing = currentPrice * baseSpacingPct / 100
 			case "ATR Based":
 				spacing = atrValue * atrMultiplier
+			case GridModeATRBand:
+				spacing = atrValue // bantlar 1 ATR aralıklı
 			default:
 				spacing = atrValue * atrMultiplier
 			}
+			respaced := respaceTo > 0 && gridInitialized && gridMode != GridModeEvenOdd
+			if respaced {
+				s.Infof("Grid respaced: %.4f -> %.4f", spacing, respaceTo)
+				spacingOverride = respaceTo
+				respaceTo = 0
+			}
+			if spacingOverride > 0 {
+				spacing = spacingOverride
+			}
+			
+			// Uzun süre işlem yoksa grid piyasa aralığına göre fazla sıkıdır - base sabit kalarak genişlet
+			barsSinceLastTrade++
+			if gridInitialized && inactivityWidenBars > 0 && barsSinceLastTrade >= inactivityWidenBars && spacingWiden < 3 {
+				spacingWiden = math.Min(spacingWiden*inactivityWidenFactor, 3)
+				barsSinceLastTrade = 0
+				s.Infof("No grid fills for %d bars, widening spacing to %.2fx", inactivityWidenBars, spacingWiden)
+			}
+			spacing *= spacingWiden
+			
+			// Gece modu
+			tradeLimit := maxConcurrentTrades
+			isNight := enableNightMode && isNightHour(e.BarTime, nightStartHour, nightEndHour)
+			if isNight != nightMode {
+				nightMode = isNight
+				s.Infof("Night mode %v (%02d:00-%02d:00 UTC)", nightMode, nightStartHour, nightEndHour)
+			}
+			if regime == RegimeVolatile {
+				spacing *= regimeVolatileSpacing
+			}
+			if nightMode {
+				spacing *= nightSpacingMultiplier
+				tradeLimit = max(1, tradeLimit/2)
+			}
+			
+			// Spread + komisyon seviye aralığını aşıyorsa grid zarar eder
+			spreadAmount := currentPrice * spreadPct / 100
+			if gridInitialized && !isProfitable(spacing, currentPrice, feePct+spreadPct) {
+				buyRestrictions |= RestrictionUnprofitable
+				sellRestrictions |= RestrictionUnprofitable
+			}
+			
+			// Bu sembol için başka bir grid ters plan yayınladıysa mirror olarak çalış
+			var plan *mirrorPlan
+			isMirror := false
+			if mirrorSymbol == "" {
+				plan, isMirror = loadMirrorPlan(s.Symbol)
+			}
+			mirrorSlots := 0
+			
+			// Fiyat sınır etrafında salınırken art arda rebalance yapılmasın
+			canRebalance := func() bool {
+				return !gridUnstable && rebalanceCooledDown(e.BarIndex, lastRebalanceBarIndex, rebalanceCooldownBars)
+			}
+			
+			// EMA kesişiminde trende ters yönde birikmiş grid'i güncel fiyata taşı
+			if enableEMACrossRebalance {
+				fastEMA := ta.EMA(e.Close, emaFast)
+				if gridInitialized && !isMirror && prevFastEMA > 0 && prevSlowEMA > 0 {
+					goldenCross, deathCross := detectEMACross(prevFastEMA, fastEMA, prevSlowEMA, trendMA)
+					buys, sells := executedLevelCounts(gridLevels.Snapshot())
+					if ((deathCross && buys > sells) || (goldenCross && sells > buys)) && canRebalance() {
+						gridBasePrice = sentimentBase()
+						markRebalanced()
+						s.Infof("EMA cross (golden=%v), grid rebalanced at %.4f (executed buys=%d, sells=%d)",
+							goldenCross, gridBasePrice, buys, sells)
+					}
+				}
+				prevFastEMA, prevSlowEMA = fastEMA, trendMA
+			}
+			
+			// Seviye bekleme süresini fiyatın doğal salınım periyoduna uydur
+			if fftAutoCooldown && e.Close.Len() >= fftLookback {
+				if period := computeDominantPeriod(e.Close, fftLookback); period >= 2 && period/2 != levelCooldownBars {
+					s.Infof("Dominant price cycle %d bars, level cooldown %d -> %d", period, levelCooldownBars, period/2)
+					levelCooldownBars = period / 2
+				}
+			}
 			
 			// Update grid levels
+			bandMode := gridMode == GridModeATRBand
+			var bands []float64
 			if gridInitialized {
-				for i := 0; i < baseGridCount && i < 8; i++ {
-					gridBuyLevels[i] = gridBasePrice - (spacing * float64(i+1))
-					gridSellLevels[i] = gridBasePrice + (spacing * float64(i+1))
+				cancelStaleLimitOrders(s, gridLevels, instanceID, currentPrice, spacing)
+				reconcilePendingLimits(s, pendingLimitOrders, gridLevels, instanceID, e.BarIndex, limitOrderMaxBars)
+				
+				if isMirror {
+					mirrorSlots = syncMirrorLevels(gridLevels, plan.levelsAt(gridBasePrice), tickSize)
+				} else if gridMode == GridModeEvenOdd {
+					updateEvenOddLevels(gridLevels, gridBasePrice, spacing, gridCount, tickSize)
+				} else if respaced {
+					// Bu bar emri açık seviyeler yerinde kalır, diğerleri yeni aralıkla fiyatlanır
+					gridLevels.ResetLayout()
+					gridLevels.Replace(CloneGridState(gridLevels.Snapshot(), gridBasePrice, spacing))
+				} else {
+					skipped := updateGridLevels(gridLevels, gridBasePrice, spacing, gridCount, tickSize, maxLevelDistancePct, neutralZonePct, levelSizing)
+					if skipped != skippedLevels {
+						s.Infof("Grid levels beyond %.1f%% of base skipped: %d", maxLevelDistancePct, skipped)
+						skippedLevels = skipped
+					}
+					
+					// Tick size ve spread'e göre çok sıkışan seviyeleri at
+					minGap := 2*tickSize + spreadAmount/2
+					if validCount := validateLevelSpacing(gridLevels.Snapshot(), minGap); validCount > 0 && validCount < gridCount {
+						s.Infof("Warning: grid levels closer than 2 ticks + half spread (%.8f), reducing grid count %d -> %d",
+							minGap, gridCount, validCount)
+						gridCount = validCount
+						trimGridLevels(gridLevels, gridCount)
+					}
+				}
+				
+				// ATR Band modunda alış seviyeleri EMA ile birlikte kayar
+				if bandMode {
+					bands = computeATRBands(e.Close, e.High, e.Low, bandEMAPeriod, atrPeriod, gridCount)
+					floatBandLevels(gridLevels, bands, tickSize)
+				}
+				
+				// Aralık değiştiyse birbirine çok yaklaşan seviyeleri birleştir
+				if !isMirror && !bandMode && spacing != lastSpacing {
+					levels := gridLevels.Snapshot()
+					if merged := mergeNearbyLevels(levels, spacing/2); len(merged) < len(levels) {
+						stats.MergeCount += len(levels) - len(merged)
+						gridLevels.Replace(merged)
+						for name := range levels {
+							if _, ok := merged[name]; !ok {
+								gridLevels.Retire(name) // sonraki barlarda yeniden oluşturulmasın
+							}
+						}
+						s.Infof("Grid levels merged: %d -> %d", len(levels), len(merged))
+					}
+					lastSpacing = spacing
+				}
+				
+				// Backfill: mevcut seviyeler önceki barlarda çalışsaydı ne olurdu (emir açılmaz, yalnızca istatistik)
+				if backfillBars > 0 && !backfillDone && gridLevels.Len() > 0 {
+					backfillDone = true
+					n := min(backfillBars, e.Close.Len()-1)
+					highs, lows, closes := seriesWindow(e.High, n+1), seriesWindow(e.Low, n+1), seriesWindow(e.Close, n+1)
+					bars := make([]backfillBar, 0, n)
+					for i := 0; i < len(closes)-1; i++ {
+						bars = append(bars, backfillBar{High: highs[i], Low: lows[i], Close: closes[i]})
+					}
+					positionCost := capitalBase * (maxSinglePosition / 100) / float64(baseGridCount)
+					backfill := backfillGrid(gridLevels.Snapshot(), bars, spacing, positionCost)
+					stats.BackfillTrades = backfill.Trades
+					stats.BackfillPnl = backfill.RealizedPnL + backfill.OpenPnL
+					s.Infof("Grid backfill (simulated, no orders) over %d bars: %d trades, PnL %.2f (realized %.2f, open %.2f)",
+						len(bars), backfill.Trades, stats.BackfillPnl, backfill.RealizedPnL, backfill.OpenPnL)
+				}
+				
+				// ATR sıçramasında seviyeler arası mesafe ortalamaya dönüş için fazla açılır - en yakın seviyeyi böl
+				if !isMirror && gridMode != GridModeEvenOdd && !bandMode && previousATR > 0 && atrValue > previousATR*atrSpikeFactor {
+					if split, ok := splitNearestLevel(gridLevels.Snapshot(), currentPrice, spacing, 2*baseGridCount); ok {
+						gridLevels.Replace(split)
+						stats.SplitLevels++
+						s.Infof("ATR spike (%.4f -> %.4f), nearest grid level split", previousATR, atrValue)
+					}
+				}
+				
+				if mirrorSymbol != "" {
+					plan = publishMirrorPlan(s.Symbol, mirrorSymbol, gridLevels.Snapshot(), gridBasePrice)
+				}
+			}
+			previousATR = atrValue
+			
+			// Açık getirisi yüksek taraftan zarardaki tarafa boyut kaydır
+			if autoBalanceBars > 0 && e.BarIndex%autoBalanceBars == 0 {
+				longs, shorts := GridLongOrders(s, gridTagPrefix(instanceID)), GridShortOrders(s, gridTagPrefix(instanceID))
+				var longPct, shortPct float64
+				if cost := positionCost(longs); cost > 0 {
+					longPct = unrealizedPnL(longs, currentPrice) / cost * 100
+				}
+				if cost := positionCost(shorts); cost > 0 {
+					shortPct = unrealizedPnL(shorts, currentPrice) / cost * 100
+				}
+				newLong, newShort := computeRebalanceRatios(longPct, shortPct)
+				if newLong != longRatio || newShort != shortRatio {
+					s.Infof("Grid side sizing: long %.1fx, short %.1fx (unrealized long %.2f%%, short %.2f%%)",
+						newLong, newShort, longPct, shortPct)
+					longRatio, shortRatio = newLong, newShort
+				}
+			}
+			
+			// Paralel grid'ler arasında Sharpe'a göre sermaye dağıtımı
+			if reallocIntervalBars > 0 && e.BarIndex%reallocIntervalBars == 0 {
+				budgetKey := gridStateKey(s.Symbol, s.TimeFrame, instanceID)
+				gridBudget.SetMinAllocPct(minAllocPct)
+				gridBudget.Register(budgetKey, stats, initialCapital)
+				if gridBudget.Len() > 1 {
+					maxSinglePosition = baseMaxSinglePosition * gridBudget.allocationScale(budgetKey)
+					s.Infof("Grid budget reallocated: max single position %.2f%% (Sharpe %.2f)", maxSinglePosition, stats.Sharpe)
+				}
+			}
+			
+			// Döngü tamamlandıysa gerçekleşmiş PnL'in bir kısmı sonraki döngünün sermayesine eklenir
+			if reinvestPnl && gridInitialized && gridCycleComplete(gridLevels.Snapshot()) {
+				if grown := growCapitalBase(initialCapital, totalRealizedPnl, reinvestPct, maxCapitalGrowth); grown != capitalBase {
+					s.Infof("Grid cycle complete, sizing capital %.2f -> %.2f (realized PnL %.2f)", capitalBase, grown, totalRealizedPnl)
+					capitalBase = grown
 				}
 			}
 			
 			// Position size calculation
-			accountEquity := 10000.0 // Gerçek hesaptan alınmalı
+			// Boyut bütçesi capitalBase'dir, ancak cüzdandaki gerçek bakiyeyi aşamaz
+			walletBalance := walletEquity(s, capitalBase+totalRealizedPnl)
+			accountEquity := math.Min(capitalBase, walletBalance)
 			basePositionSize := accountEquity * (maxSinglePosition / 100) / float64(baseGridCount)
 			
-			// Grid execution - Buy levels
-			for i := 0; i < baseGridCount && i < 8; i++ {
-				if !gridLevelUsed[i] && currentLow <= gridBuyLevels[i] {
-					tag := fmt.Sprintf("GridBuy_%d", i+1)
-					
-					s.OpenOrder(&strat.EnterReq{
-						Tag:    tag,
-						Short:  false,
-						Amount: basePositionSize,
-					})
-					
-					gridLevelUsed[i] = true
-					totalGridTrades++
-					
-					s.Infof("Grid Buy Level %d: %.4f (Size: %.2f)", i+1, gridBuyLevels[i], basePositionSize)
+			// Delta hedge: net pozisyon hedeften saptıkça bir tarafın emirleri büyür, diğerininki küçülür
+			hedgeLong, hedgeShort := 1.0, 1.0
+			if deltaHedgeMode == DeltaHedgeSize {
+				hedgeLong, hedgeShort = computeDeltaAdjustment(portfolioDelta(s, instanceID), targetDelta, basePositionSize)
+			}
+			
+			// Seviye tutarlılık kontrolü - sorun varsa bu bar emir açma
+			touchTrigger := gridMode == GridModeEvenOdd || bandMode || isMirror // seviyeler base'in her iki yanında
+			stateIssues := validateGridState(gridBasePrice, gridLevels.Snapshot(), !touchTrigger)
+			for _, issue := range stateIssues {
+				s.Infof("Grid state invalid, execution skipped: %s", issue)
+			}
+			// Dolan alış seviyesi arttıkça sonraki alışların TP'sini uzat
+			if tpType == TPTypeATR {
+				filledBuys, filledSells := executedLevelCounts(gridLevels.Snapshot())
+				gridLevels.Replace(ratchetTakeProfits(gridLevels.Snapshot(), filledBuys, filledSells, tpRatchetPct, atrValue, takeProfitATR))
+				if mult := ratchetMultiplier(filledBuys, tpRatchetPct); mult != ratchetBuyMult {
+					s.Infof("TP ratchet: %.2fx base take-profit for unfilled buys (%d filled)", mult, filledBuys)
+					ratchetBuyMult = mult
+				}
+				if mult := ratchetMultiplier(filledSells, tpRatchetPct); mult != ratchetSellMult {
+					s.Infof("TP ratchet: %.2fx base take-profit for unfilled sells (%d filled)", mult, filledSells)
+					ratchetSellMult = mult
 				}
 			}
 			
-			// Grid execution - Sell levels
-			for i := 0; i < baseGridCount && i < 8; i++ {
-				levelIndex := i + 8
-				if !gridLevelUsed[levelIndex] && currentHigh >= gridSellLevels[i] {
-					tag := fmt.Sprintf("GridSell_%d", i+1)
-					
-					s.OpenOrder(&strat.EnterReq{
-						Tag:    tag,
-						Short:  true,
-						Amount: basePositionSize,
-					})
-					
-					gridLevelUsed[levelIndex] = true
-					totalGridTrades++
-					
-					s.Infof("Grid Sell Level %d: %.4f (Size: %.2f)", i+1, gridSellLevels[i], basePositionSize)
+			instanceOrders := GridOpenOrders(s, gridTagPrefix(instanceID))
+			openTrades := len(instanceOrders)
+			// Instance'ın açık pozisyonlarının toplam maliyeti max_portfolio_risk'e ulaştıysa yeni seviye açılmaz
+			if accountEquity > 0 && positionCost(instanceOrders)/accountEquity*100 >= maxPortfolioRisk {
+				tradeLimit = min(tradeLimit, openTrades)
+			}
+			levelSlots := gridCount
+			if gridMode == GridModeEvenOdd {
+				levelSlots = 2 * gridCount // alış ve satışlar base'in iki yanında
+			}
+			if isMirror {
+				levelSlots = mirrorSlots
+			} else {
+				levelSlots = max(levelSlots, maxLevelIndex(gridLevels.Snapshot())) // bölünmüş seviyeler
+			}
+			// Mirror çiftinde max_concurrent_trades iki sembol arasında paylaşılır
+			if plan != nil {
+				openTrades += plan.sharedOpen(s.Symbol, openTrades)
+			}
+			switch {
+			case !gridInitialized:
+				stats.Skips.GridNotInit++
+			case gridLevels.Len() == 0:
+				stats.Skips.NoLevels++
+			case len(stateIssues) > 0 || (buyRestrictions != 0 && sellRestrictions != 0):
+				stats.Skips.CannotTrade++
+			}
+			if len(stateIssues) == 0 {
+				levelsExecutedThisBar := 0
+				
+				// Grid execution - Buy levels
+				for i := 1; i <= levelSlots; i++ {
+					if shouldThrottle(levelsExecutedThisBar, maxLevelsPerBar) {
+						break
+					}
+					name := levelName(LevelBuy, i)
+					level, ok := gridLevels.Get(name)
+					triggered := ok && currentLow <= level.Price
+					if touchTrigger {
+						triggered = triggered && currentHigh >= level.Price // base üstündeki alışlar da dokunuşla tetiklenir
+					}
+					if ok && dynamicSpacing {
+						triggered = triggered || isLevelTriggered(level.Price, currentLow, currentHigh, atrValue, dynamicZoneATR)
+					}
+					if requireCloseConfirm {
+						triggered = triggered && closeConfirmed(LevelBuy, level.Price, currentPrice) // yalnızca fitil değdiyse tetikleme
+					}
+					ready := ok && levelReady(level, e.BarIndex, maxFillsPerLevel, levelCooldownBars)
+					if bandMode {
+						// Band fiyatı sabit olmadığından Executed yerine seviye bekleme süresi kullanılır;
+						// kapanış bandın altına ilk kez indiğinde tetiklenir
+						ready = ok && (level.EntryBarIndex == 0 || e.BarIndex-level.EntryBarIndex >= levelCooldownBars)
+						triggered = ok && i <= len(bands) && currentPrice < bands[i-1] &&
+							(i > len(prevBands) || e.Close.Last(1) >= prevBands[i-1])
+					}
+					if ok && buyRestrictions == 0 && level.Active && ready && triggered && openTrades < tradeLimit {
+						req := &strat.EnterReq{
+							Tag:    levelTag(instanceID, LevelBuy, i),
+							Short:  false,
+							Amount: (basePositionSize*level.SizeMultiplier + level.PartialFillSize) * longRatio * hedgeLong,
+						}
+						if orderType == OrderTypeLimit {
+							req.Limit = level.Price
+							if slip != nil {
+								req.Limit = slip(level.Price)
+							}
+						} else if slip != nil {
+							req.Limit = slip(currentPrice)
+						}
+						if leverage > 1 {
+							liq := projectedLiquidation(GridLongOrders(s, gridTagPrefix(instanceID)), currentPrice, req.Amount, leverage, mmRate)
+							if liq > currentPrice*(1-liquidationSafetyMargin) {
+								s.Infof("Grid Buy Level %d blocked: projected liquidation %.4f within %.0f%% of price",
+									i, liq, liquidationSafetyMargin*100)
+								continue
+							}
+						}
+						requested := req.Amount
+						level.PartialFillSize = 0
+						if err := s.OpenOrder(req); err != nil {
+							available := walletBalance
+							notifyCapitalShortfall(s, cfg.OnCapitalShortfall, req, err, currentPrice, available)
+							if isCapitalError(err) && attemptPartialFill(s, req, currentPrice, available, minPartialFillPct) == nil {
+								level.PartialFillCount++
+								level.PartialFillSize = requested - req.Amount
+							} else if err = retryOrder(s, req, err, maxOrderRetries); err != nil {
+								s.Infof("Grid Buy Level %d not executed: %v", i, err)
+								continue
+							}
+						}
+						
+						recordLevelFill(&level, e.BarIndex)
+						gridLevels.Set(name, level)
+						totalGridTrades++
+						stats.TotalFills++
+						barsSinceLastTrade = 0
+						openTrades++
+						levelsExecutedThisBar++
+						session.recordEntry(req.Tag, e.BarIndex)
+						if orderType == OrderTypeLimit {
+							pendingLimitOrders[name] = pendingLimit{req: req, placedBar: e.BarIndex}
+						}
+						if stats.HeatMap != nil {
+							stats.HeatMap.Record(level.Price)
+						}
+						if activityMap != nil {
+							activityMap.Record(e.BarIndex, level.Price)
+						}
+						
+						s.Infof("Grid Buy Level %d: %.4f (Size: %.2f)", i, level.Price, req.Amount)
+					}
+				}
+				
+				// Grid execution - Sell levels
+				for i := 1; i <= levelSlots; i++ {
+					if shouldThrottle(levelsExecutedThisBar, maxLevelsPerBar) {
+						break
+					}
+					name := levelName(LevelSell, i)
+					level, ok := gridLevels.Get(name)
+					triggered := ok && currentHigh >= level.Price
+					if touchTrigger {
+						triggered = triggered && currentLow <= level.Price
+					}
+					if ok && dynamicSpacing {
+						triggered = triggered || isLevelTriggered(level.Price, currentLow, currentHigh, atrValue, dynamicZoneATR)
+					}
+					if requireCloseConfirm {
+						triggered = triggered && closeConfirmed(LevelSell, level.Price, currentPrice)
+					}
+					ready := ok && levelReady(level, e.BarIndex, maxFillsPerLevel, levelCooldownBars)
+					if ok && !bandMode && sellRestrictions == 0 && level.Active && ready && triggered && openTrades < tradeLimit {
+						req := &strat.EnterReq{
+							Tag:    levelTag(instanceID, LevelSell, i),
+							Short:  true,
+							Amount: (basePositionSize*level.SizeMultiplier + level.PartialFillSize) * shortRatio * hedgeShort,
+						}
+						if orderType == OrderTypeLimit {
+							req.Limit = level.Price
+							if slip != nil {
+								req.Limit = slip(level.Price)
+							}
+						} else if slip != nil {
+							req.Limit = slip(currentPrice)
+						}
+						requested := req.Amount
+						level.PartialFillSize = 0
+						if err := s.OpenOrder(req); err != nil {
+							available := walletBalance
+							notifyCapitalShortfall(s, cfg.OnCapitalShortfall, req, err, currentPrice, available)
+							if isCapitalError(err) && attemptPartialFill(s, req, currentPrice, available, minPartialFillPct) == nil {
+								level.PartialFillCount++
+								level.PartialFillSize = requested - req.Amount
+							} else if err = retryOrder(s, req, err, maxOrderRetries); err != nil {
+								s.Infof("Grid Sell Level %d not executed: %v", i, err)
+								continue
+							}
+						}
+						
+						recordLevelFill(&level, e.BarIndex)
+						gridLevels.Set(name, level)
+						totalGridTrades++
+						stats.TotalFills++
+						barsSinceLastTrade = 0
+						openTrades++
+						levelsExecutedThisBar++
+						session.recordEntry(req.Tag, e.BarIndex)
+						if orderType == OrderTypeLimit {
+							pendingLimitOrders[name] = pendingLimit{req: req, placedBar: e.BarIndex}
+						}
+						if stats.HeatMap != nil {
+							stats.HeatMap.Record(level.Price)
+						}
+						if activityMap != nil {
+							activityMap.Record(e.BarIndex, level.Price)
+						}
+						
+						s.Infof("Grid Sell Level %d: %.4f (Size: %.2f)", i, level.Price, req.Amount)
+					}
+				}
+			}
+			
+			prevBands = bands
+			
+			// Stop-loss and take-profit management; komisyonu karşılamayan take-profit ertelenir
+			var feeGuard func(*core.Order, float64) bool
+			if feePct > 0 {
+				feeGuard = func(od *core.Order, price float64) bool {
+					if isProfitableToClose(od, price, feePct) {
+						return true
+					}
+					stats.FeeProtectedSkips++
+					return false
+				}
+			}
+			closed := manageTradingOrders(s, gridLevels, instanceID, atrValue, stopLossATR, takeProfitATR, tpType, takeProfitPct, breakevenOnFirstTP, slip, feeGuard)
+			
+			// Parabolic SAR bu barda döndüyse ters yöndeki dolmuş pozisyonları kapat
+			if enablePsarExit {
+				if _, psarUp, flipped := computeParabolicSAR(e.High, e.Low, psarStep, psarMax); flipped {
+					done := make(map[*core.Order]bool, len(closed))
+					for _, trade := range closed {
+						done[trade.Order] = true
+					}
+					orders := GridLongOrders(s, gridTagPrefix(instanceID))
+					if psarUp {
+						orders = GridShortOrders(s, gridTagPrefix(instanceID))
+					}
+					for _, order := range orders {
+						if order.Status != core.OdStatusFull || done[order] {
+							continue
+						}
+						closed = append(closed, closeGridOrder(s, order, instanceID, "psar_exit", currentPrice))
+						s.Infof("PSAR exit for %s at %.4f", order.Tag, currentPrice)
+					}
+				}
+			}
+			
+			// max_hold_bars'dan uzun tutulan pozisyonları PnL'den bağımsız kapat
+			if maxHoldBars > 0 {
+				done := make(map[*core.Order]bool, len(closed))
+				for _, trade := range closed {
+					done[trade.Order] = true
+				}
+				for _, order := range GridOpenOrders(s, gridTagPrefix(instanceID)) {
+					if order.Status != core.OdStatusFull || done[order] {
+						continue
+					}
+					level, ok := levelForOrder(gridLevels, order, instanceID)
+					if !ok || !positionAged(e.BarIndex, level.EntryBarIndex, maxHoldBars) {
+						continue
+					}
+					closed = append(closed, closeGridOrder(s, order, instanceID, "max_age_exit", currentPrice))
+					stats.AgedExits++
+					s.Infof("Max age exit for %s after %d bars at %.4f", order.Tag, e.BarIndex-level.EntryBarIndex, currentPrice)
 				}
 			}
+			stillOpen := openLevelNames(s, instanceID, closed)
+			for _, trade := range closed {
+				totalRealizedPnl += trade.PnL
+				session.recordClose(trade, e.BarIndex)
+				returns.Add(trade.Return)
+				if trade.Level != "" {
+					recordLevelOutcome(s, gridLevels, stats, trade.Level, trade.PnL > 0, minLevelWinRate, stillOpen[trade.Level])
+				}
+			}
+			releaseClosedLevels(s, gridLevels, instanceID, closed)
+			if len(closed) > 0 && barSecs > 0 {
+				stats.Sharpe = returns.Sharpe(barSecs)
+				stats.Sortino = returns.Sortino(barSecs)
+			}
+			
+			// Eşzamanlı drawdown: grid başladığından beri en düşük equity
+			if gridInitialized {
+				equity := initialCapital + totalRealizedPnl + unrealizedPnL(GridOpenOrders(s, gridTagPrefix(instanceID)), currentPrice)
+				stats.MaxConcurrentDD = math.Max(stats.MaxConcurrentDD, initialCapital-equity)
+			}
+			if len(closed) > 0 {
+				stats.RARoC = computeRARoC(totalRealizedPnl, stats.MaxConcurrentDD)
+			}
+			
+			// Perpetual funding: maliyet gerçekleşmiş PnL'den düşülür
+			if fundingIntervalBars > 0 && barSecs > 0 && e.BarIndex%fundingIntervalBars == 0 {
+				intervalHours := float64(fundingIntervalBars) * float64(barSecs) / 3600
+				fundingPct := fundingRatePct * intervalHours
+				funding := applyFunding(GridLongOrders(s, gridTagPrefix(instanceID)), fundingPct, true) +
+					applyFunding(GridShortOrders(s, gridTagPrefix(instanceID)), fundingPct, false)
+				if funding != 0 {
+					totalRealizedPnl -= funding
+					stats.FundingCostTotal += funding
+					s.Infof("Grid funding applied: %.4f (total %.4f)", funding, stats.FundingCostTotal)
+				}
+			}
+			
+			// Paralel instance'lar arası PnL korelasyonu - lider instance 500 barda bir loglar
+			if trackCorrelation {
+				barPnl := 0.0
+				for _, trade := range closed {
+					barPnl += trade.PnL
+				}
+				updateCorrelationMatrix(s.Symbol, barPnl, &gridPnLStore)
+				if e.BarIndex%500 == 0 && isCorrelationLeader(s.Symbol, &gridPnLStore) {
+					s.Infof("Grid PnL correlation matrix:\n%s", formatMatrix(computeMatrix(&gridPnLStore)))
+				}
+			}
+			
+			// Normalize volatilite ölçüleri
+			bbUpper, _, bbLower := ta.BOLL(e.Close, 20, 2.0)
+			stats.ATRRatio = atrValue / currentPrice
+			if width, ok := bollingerWidth(bbUpper, bbLower); ok {
+				stats.BBWidth = width
+			}
 			
-			// Stop-loss and take-profit management
-			manageTradingOrders(s, atrValue, stopLossATR, takeProfitATR)
+			// Performans düşerse (20 bar üst üste düşük Sharpe) volatiliteye uygun moda geç
+			if returns.Len() >= 2 && stats.Sharpe < modeSwitchSharpe {
+				lowSharpeBars++
+			} else {
+				lowSharpeBars = 0
+			}
+			if lowSharpeBars >= 20 {
+				dailyVol := stats.ATRRatio * 100
+				if useBBWidthVolatility {
+					dailyVol = stats.BBWidth / 4 * 100 // bant genişliği = 4 standart sapma
+				}
+				if barSecs > 0 {
+					dailyVol *= math.Sqrt(86400 / float64(barSecs))
+				}
+				if newMode := selectBestGridMode(gridMode, dailyVol); newMode != gridMode {
+					s.Infof("Grid mode switch: %s -> %s (Sharpe=%.2f, Daily Vol=%.2f%%)", gridMode, newMode, stats.Sharpe, dailyVol)
+					gridMode = newMode
+				}
+				lowSharpeBars = 0
+			}
+			
+			// Grid seviyesinde kâr hedefi / zarar limiti
+			if gridInitialized {
+				hitTarget, hitStop := checkPnLBounds(totalRealizedPnl, initialCapital, pnlTargetPct, pnlStopPct)
+				if hitTarget || hitStop {
+					if hitTarget {
+						s.Infof("Grid PnL target reached: %.2f (%.2f%%)", totalRealizedPnl, totalRealizedPnl/initialCapital*100)
+					} else {
+						s.Infof("Grid PnL stop reached: %.2f (%.2f%%)", totalRealizedPnl, totalRealizedPnl/initialCapital*100)
+					}
+					s.CloseOrders(&strat.ExitReq{Tag: "grid_pnl_bound", Orders: GridOpenOrders(s, gridTagPrefix(instanceID)), ExitRate: 1.0})
+					gridLevels.Replace(nil)
+					gridInitialized = false
+					gridHalted = true
+					haltedSessionCount = 0
+				}
+			}
+			
+			// Durdurulan grid'i belirli sayıda yeni session sonra yeniden başlat
+			if gridHalted && newSession {
+				haltedSessionCount++
+				if checkAutoRestart(s, haltedSessionCount, autoRestartSessions) {
+					gridHalted = false
+					haltedSessionCount = 0
+					totalRealizedPnl = 0 // yeni döngü sıfırdan başlar
+				}
+			}
 			
 			// Periodic status
 			if e.BarIndex%50 == 0 {
@@ -140,60 +1231,307 @@ func GridPro(pol *config.RunPolicyConfig) *strat.TradeStrat {
 				if isUptrend {
 					trend = "UP"
 				}
-				s.Infof("Grid Status: Price=%.4f, Base=%.4f, Trades=%d, Trend=%s", 
-					currentPrice, gridBasePrice, totalGridTrades, trend)
+				s.Infof("Grid Status: Price=%.4f, Base=%.4f, Trades=%d, Trend=%s (%.2f%%), Bars Since Init=%d", 
+					currentPrice, gridBasePrice, totalGridTrades, trend, trendStrength, e.BarIndex-gridInitBarIndex)
+				s.Infof("Grid Performance: Sharpe=%.2f, Sortino=%.2f, RARoC=%.2f, Realized PnL=%.2f (Funding=%.2f), Bars Since Trade=%d",
+					stats.Sharpe, stats.Sortino, stats.RARoC, totalRealizedPnl, stats.FundingCostTotal, barsSinceLastTrade)
+				s.Infof("Grid Skips: TooFewBars=%d, NaN=%d, NotInit=%d, CannotTrade=%d, NoLevels=%d, LowVolume=%d",
+					stats.Skips.TooFewBars, stats.Skips.NaNIndicators, stats.Skips.GridNotInit,
+					stats.Skips.CannotTrade, stats.Skips.NoLevels, stats.Skips.LowVolume)
+				if buyRestrictions != 0 || sellRestrictions != 0 || initRestrictions != 0 {
+					s.Infof("Grid Restrictions: Buy=%s, Sell=%s, Init=%s", buyRestrictions, sellRestrictions, initRestrictions)
+				}
+				
+				if storage != nil {
+					snap := GridSnapshot{
+						Version:     CurrentStateVersion,
+						BasePrice:   gridBasePrice,
+						Initialized: gridInitialized,
+						Halted:      gridHalted,
+						RealizedPnl: totalRealizedPnl,
+						Levels:      gridLevels.Snapshot(),
+						BarTime:     e.BarTime,
+					}
+					if err := storage.Save(gridStateKey(s.Symbol, s.TimeFrame, instanceID), snap); err != nil {
+						s.Infof("Grid state save failed: %v", err)
+					}
+				}
+				
+				// Aynı barda birlikte tetiklenebilecek seviye kümeleri; toplam boyut tek pozisyon limitinin 2 katını aşmasın
+				stats.LevelClusters = clusterGridLevels(gridLevels.Snapshot(), atrValue)
+				for _, cluster := range stats.LevelClusters {
+					if cluster.TotalSize*basePositionSize > 2*accountEquity*maxSinglePosition/100 {
+						s.Infof("Warning: grid level concentration at %.4f: %v (size %.2f)",
+							cluster.CenterPrice, cluster.LevelNames, cluster.TotalSize*basePositionSize)
+					}
+				}
+				
+				// Dolumlar tek tarafta birikiyor mu?
+				buys, sells := executedLevelCounts(gridLevels.Snapshot())
+				if updateDriftMetrics(&stats.Drift, buys, sells, maxDriftRatio) {
+					s.Infof("Grid drift detected: %d buy / %d sell levels open (ratio %.2f)", buys, sells, stats.Drift.DriftRatio)
+					if cfg.OnDriftDetected != nil && cfg.OnDriftDetected(s, stats.Drift) && gridInitialized && !isMirror && canRebalance() {
+						gridBasePrice = sentimentBase()
+						markRebalanced()
+						s.Infof("Grid rebalanced at %.4f after drift", gridBasePrice)
+					}
+				}
+				
+				// Tutarsız durumu onar; base çok kaydıysa grid'i güncel fiyata taşı
+				if gridInitialized {
+					if _, recenter := runHealthCheck(s, gridBasePrice, currentPrice, gridLevels, instanceID); recenter && !isMirror && canRebalance() {
+						gridBasePrice = sentimentBase()
+						markRebalanced()
+						s.Infof("Grid recentered at price: %.4f", gridBasePrice)
+					}
+				}
+			}
+			
+			// En çok ziyaret edilen fiyat bölgeleri; base'ten uzaksa yeniden ortalama öner
+			if stats.HeatMap != nil && e.BarIndex%500 == 0 {
+				top := stats.HeatMap.TopN(5)
+				for rank, bin := range top {
+					s.Infof("Grid Heat Map #%d: %.4f (%d hits)", rank+1, bin.Price, bin.Count)
+				}
+				if len(top) > 0 && gridInitialized && math.Abs(top[0].Price-gridBasePrice) > 2*spacing {
+					s.Infof("Grid Heat Map: most visited price %.4f is far from base %.4f, consider recentering",
+						top[0].Price, gridBasePrice)
+				}
+			}
+			
+			// Redis'e anlık durum (TTL = 3 bar, instance durursa key kendiliğinden silinir)
+			if exporter != nil {
+				viz := BuildVisualization(s, gridBasePrice, gridLevels)
+				ttl := 3 * time.Duration(barSecs) * time.Second
+				if err := exporter.Export(gridExportKey(s.Symbol, s.TimeFrame), *stats, viz, ttl); err != nil {
+					s.Infof("Grid Redis export failed: %v", err)
+				}
+			}
+			
+			// Emir audit kaydı: açılan/kapanan grid emirleri
+			if auditLog != nil {
+				portfolioRisk := 0.0
+				if capitalBase > 0 {
+					portfolioRisk = positionCost(GridOpenOrders(s, gridTagPrefix(instanceID))) / capitalBase * 100
+				}
+				auditLog.Observe(e.BarTime, GridOpenOrders(s, gridTagPrefix(instanceID)), currentPrice, portfolioRisk)
+				if auditFlushBars > 0 && e.BarIndex%auditFlushBars == 0 {
+					if err := auditLog.Flush(); err != nil {
+						s.Infof("Grid audit log flush failed: %v", err)
+					}
+				}
+			}
+			
+			// Dashboard için grid görüntüsü
+			if visOutput != "" && visIntervalBars > 0 && e.BarIndex%visIntervalBars == 0 {
+				viz := BuildVisualization(s, gridBasePrice, gridLevels)
+				if err := writeVisualization(visOutput, viz); err != nil {
+					s.Infof("Grid visualization write failed: %v", err)
+				}
+			}
+		},
+		
+		OnShutDown: func(s *strat.StratJob) {
+			unregisterGridStats(s)
+			if stopConfigWatch != nil {
+				stopConfigWatch()
+			}
+			dropLiveConfig(pol)
+			if auditLog != nil {
+				if err := auditLog.Flush(); err != nil {
+					s.Infof("Grid audit log flush failed: %v", err)
+				}
+			}
+			if activityMap == nil {
+				return
+			}
+			if err := activityMap.WriteCSV(heatmapPath); err != nil {
+				s.Infof("Grid heatmap export failed: %v", err)
+			} else {
+				s.Infof("Grid heatmap written to %s", heatmapPath)
 			}
 		},
 	}
 }
 
-// Helper function for trade management
-func manageTradingOrders(s *strat.StratJob, atrValue, stopLossATR, takeProfitATR float64) {
+// closedTrade - bu bar kapatılan bir grid emrinin özeti
+type closedTrade struct {
+	Order  *core.Order
+	Level  string // bağlı grid seviyesi yoksa boş
+	Reason string
+	PnL    float64
+	Return float64 // giriş maliyetine göre getiri
+}
+
+// closeGridOrder - emri kapatır ve yaklaşık gerçekleşmiş PnL'i hesaplar
+func closeGridOrder(s *strat.StratJob, order *core.Order, instanceID, reason string, price float64) closedTrade {
+	s.CloseOrders(&strat.ExitReq{
+		Tag:    reason + "_" + order.Tag,
+		Orders: []*core.Order{order},
+	})
+	pnl := (price - order.AvgPrice) * order.Amount
+	if order.Short {
+		pnl = -pnl
+	}
+	trade := closedTrade{Order: order, Reason: reason, PnL: pnl}
+	if cost := order.AvgPrice * order.Amount; cost > 0 {
+		trade.Return = pnl / cost
+	}
+	if name, ok := levelNameFromTag(order.Tag, instanceID); ok {
+		trade.Level = name
+	}
+	return trade
+}
+
+// Helper function for trade management - bu bar kapatılan emirleri döndürür.
+// slip nil değilse stop ve hedef fiyatlarına slippage uygulanır. canTakeProfit nil değilse
+// false döndüğü emirlerde take-profit bu bar ertelenir; stop-loss her zaman uygulanır.
+func manageTradingOrders(s *strat.StratJob, levels *GridLevelMap, instanceID string, atrValue, stopLossATR, takeProfitATR float64,
+	tpType int, takeProfitPct float64, breakevenOnTP bool, slip func(float64) float64,
+	canTakeProfit func(*core.Order, float64) bool) []closedTrade {
+	currentPrice := s.Env.Close.Last(0)
+	var closed []closedTrade
+	levelSnapshot := levels.Snapshot()
+	
 	// Long positions için stop-loss ve take-profit
-	for _, order := range s.LongOrders {
+	for _, order := range GridLongOrders(s, gridTagPrefix(instanceID)) {
 		if order.Status == core.OdStatusFull {
 			stopPrice := order.AvgPrice - (atrValue * stopLossATR)
-			profitPrice := order.AvgPrice + (atrValue * takeProfitATR)
+			profitPrice := computeTP(order, tpType, levelSnapshot, atrValue, takeProfitATR, takeProfitPct)
 			
-			currentPrice := s.Env.Close.Last(0)
+			level, hasLevel := levelForOrder(levels, order, instanceID)
+			if hasLevel && level.StopLoss > 0 {
+				stopPrice = math.Max(stopPrice, level.StopLoss)
+			}
+			if hasLevel && level.TakeProfit > 0 {
+				profitPrice = level.TakeProfit
+			}
+			if slip != nil {
+				stopPrice, profitPrice = slip(stopPrice), slip(profitPrice)
+			}
 			
 			if currentPrice <= stopPrice {
-				s.CloseOrders(&strat.ExitReq{
-					Tag:    "stop_loss_" + order.Tag,
-					Orders: []*core.Order{order},
-				})
+				closed = append(closed, closeGridOrder(s, order, instanceID, "stop_loss", currentPrice))
 				s.Infof("Stop-loss triggered for %s at %.4f", order.Tag, currentPrice)
-			} else if currentPrice >= profitPrice {
-				s.CloseOrders(&strat.ExitReq{
-					Tag:    "take_profit_" + order.Tag,
-					Orders: []*core.Order{order},
-				})
+			} else if currentPrice >= profitPrice && (canTakeProfit == nil || canTakeProfit(order, currentPrice)) {
+				closed = append(closed, closeGridOrder(s, order, instanceID, "take_profit", currentPrice))
 				s.Infof("Take-profit triggered for %s at %.4f", order.Tag, currentPrice)
+				if breakevenOnTP && hasLevel {
+					adjustSiblingBreakevens(s, level, levels, instanceID)
+				}
 			}
 		}
 	}
 	
 	// Short positions için stop-loss ve take-profit
-	for _, order := range s.ShortOrders {
+	for _, order := range GridShortOrders(s, gridTagPrefix(instanceID)) {
 		if order.Status == core.OdStatusFull {
 			stopPrice := order.AvgPrice + (atrValue * stopLossATR)
-			profitPrice := order.AvgPrice - (atrValue * takeProfitATR)
+			profitPrice := computeTP(order, tpType, levelSnapshot, atrValue, takeProfitATR, takeProfitPct)
 			
-			currentPrice := s.Env.Close.Last(0)
+			level, hasLevel := levelForOrder(levels, order, instanceID)
+			if hasLevel && level.StopLoss > 0 {
+				stopPrice = math.Min(stopPrice, level.StopLoss)
+			}
+			if hasLevel && level.TakeProfit > 0 {
+				profitPrice = level.TakeProfit
+			}
+			if slip != nil {
+				stopPrice, profitPrice = slip(stopPrice), slip(profitPrice)
+			}
 			
 			if currentPrice >= stopPrice {
-				s.CloseOrders(&strat.ExitReq{
-					Tag:    "stop_loss_" + order.Tag,
-					Orders: []*core.Order{order},
-				})
+				closed = append(closed, closeGridOrder(s, order, instanceID, "stop_loss", currentPrice))
 				s.Infof("Stop-loss triggered for %s at %.4f", order.Tag, currentPrice)
-			} else if currentPrice <= profitPrice {
-				s.CloseOrders(&strat.ExitReq{
-					Tag:    "take_profit_" + order.Tag,
-					Orders: []*core.Order{order},
-				})
+			} else if currentPrice <= profitPrice && (canTakeProfit == nil || canTakeProfit(order, currentPrice)) {
+				closed = append(closed, closeGridOrder(s, order, instanceID, "take_profit", currentPrice))
 				s.Infof("Take-profit triggered for %s at %.4f", order.Tag, currentPrice)
+				if breakevenOnTP && hasLevel {
+					adjustSiblingBreakevens(s, level, levels, instanceID)
+				}
+			}
+		}
+	}
+	return closed
+}
+
+// computeTP - emrin take-profit fiyatı.
+// TPTypeNextLevel'da long için girişin üstündeki, short için altındaki en yakın grid seviyesi
+// kullanılır; böyle bir seviye yoksa ATR bazlı hedefe düşülür.
+func computeTP(od *core.Order, tpType int, levels map[string]GridLevel, atr, tpATRMult, tpPct float64) float64 {
+	sign := 1.0
+	if od.Short {
+		sign = -1.0
+	}
+	switch tpType {
+	case TPTypeNextLevel:
+		next, found := 0.0, false
+		for _, level := range levels {
+			dist := (level.Price - od.AvgPrice) * sign
+			if dist > 0 && (!found || dist < (next-od.AvgPrice)*sign) {
+				next, found = level.Price, true
 			}
 		}
+		if found {
+			return next
+		}
+	case TPTypeFixedPct:
+		return od.AvgPrice * (1 + sign*tpPct/100)
+	}
+	return od.AvgPrice + sign*atr*tpATRMult
+}
+
+// levelForOrder - emrin ait olduğu grid seviyesini bulur
+func levelForOrder(levels *GridLevelMap, order *core.Order, instanceID string) (GridLevel, bool) {
+	name, ok := levelNameFromTag(order.Tag, instanceID)
+	if !ok {
+		return GridLevel{}, false
+	}
+	return levels.Get(name)
+}
+
+// adjustSiblingBreakevens - TP alan seviyeyle aynı yöndeki diğer açık emirlerin
+// stop-loss'unu giriş fiyatına (breakeven) çeker
+func adjustSiblingBreakevens(s *strat.StratJob, filledLevel GridLevel, levels *GridLevelMap, instanceID string) {
+	orders := GridLongOrders(s, gridTagPrefix(instanceID))
+	if filledLevel.Type == LevelSell {
+		orders = GridShortOrders(s, gridTagPrefix(instanceID))
+	}
+	for _, order := range orders {
+		if order.Status != core.OdStatusFull {
+			continue
+		}
+		level, ok := levelForOrder(levels, order, instanceID)
+		if !ok || level.Name == filledLevel.Name || level.StopLoss > 0 {
+			continue
+		}
+		level.StopLoss = order.AvgPrice
+		levels.Set(level.Name, level)
+		s.Infof("Breakeven stop for %s moved to %.4f after TP on %s", order.Tag, level.StopLoss, filledLevel.Name)
+	}
+}
+
+// rebalanceCooledDown - son rebalance'tan (ya da grid kurulumundan) bu yana cooldownBars bar geçti mi?
+func rebalanceCooledDown(barIndex, lastRebalanceBarIndex, cooldownBars int) bool {
+	return barIndex-lastRebalanceBarIndex >= cooldownBars
+}
+
+// isNewSession - iki bar zamanı farklı UTC günlerine mi ait?
+func isNewSession(prevTime, curTime int64) bool {
+	prev := time.Unix(prevTime, 0).UTC()
+	cur := time.Unix(curTime, 0).UTC()
+	return prev.YearDay() != cur.YearDay() || prev.Year() != cur.Year()
+}
+
+// isNightHour - bar saati (UTC) [startH, endH) aralığında mı? Gece yarısını aşan
+// aralıklar (22-6 gibi) desteklenir, startH == endH gece modunu kapatır.
+func isNightHour(barTime int64, startH, endH int) bool {
+	hour := time.Unix(barTime, 0).UTC().Hour()
+	if startH == endH {
+		return false
+	}
+	if startH < endH {
+		return hour >= startH && hour < endH
 	}
+	return hour >= startH || hour < endH
 }
@@ -0,0 +1,445 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+
+	ta "github.com/banbox/banta"
+)
+
+// assertFloat - got ile want'ı 1e-9 toleransla karşılaştırır; NaN yalnızca NaN'a eşittir
+func assertFloat(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.IsNaN(want) && math.IsNaN(got) {
+		return
+	}
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %.10f, want %.10f", name, got, want)
+	}
+}
+
+// assertLevelPrices - map'te tam olarak want'taki seviyeler, verilen fiyatlarla bulunmalı
+func assertLevelPrices(t *testing.T, levels map[string]GridLevel, want map[string]float64) {
+	t.Helper()
+	if len(levels) != len(want) {
+		t.Errorf("got %d levels, want %d: %v", len(levels), len(want), levels)
+	}
+	for name, price := range want {
+		level, ok := levels[name]
+		if !ok {
+			t.Errorf("level %s missing", name)
+			continue
+		}
+		assertFloat(t, name+".Price", level.Price, price)
+	}
+}
+
+func newSeries(values ...float64) *ta.Series {
+	return &ta.Series{Data: values}
+}
+
+func TestUpdateGridLevels(t *testing.T) {
+	tests := []struct {
+		name           string
+		base, spacing  float64
+		count          int
+		tickSize       float64
+		maxDistancePct float64
+		neutralZonePct float64
+		wantPrices     map[string]float64
+		wantSkipped    int
+	}{
+		{
+			name: "fixed spacing", base: 100, spacing: 1, count: 3,
+			wantPrices: map[string]float64{"B1": 99, "B2": 98, "B3": 97, "S1": 101, "S2": 102, "S3": 103},
+		},
+		{
+			name: "snapped to tick", base: 100, spacing: 0.33, count: 1, tickSize: 0.1,
+			wantPrices: map[string]float64{"B1": 99.7, "S1": 100.3},
+		},
+		{
+			name: "beyond max distance skipped", base: 100, spacing: 1, count: 3, maxDistancePct: 1.5,
+			wantPrices:  map[string]float64{"B1": 99, "S1": 101},
+			wantSkipped: 4,
+		},
+		{
+			name: "neutral zone left empty", base: 100, spacing: 1, count: 2, neutralZonePct: 1.5,
+			wantPrices: map[string]float64{"B2": 98, "S2": 102},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := newGridLevelMap()
+			skipped := updateGridLevels(levels, tt.base, tt.spacing, tt.count, tt.tickSize, tt.maxDistancePct, tt.neutralZonePct, nil)
+			if skipped != tt.wantSkipped {
+				t.Errorf("skipped = %d, want %d", skipped, tt.wantSkipped)
+			}
+			assertLevelPrices(t, levels.Snapshot(), tt.wantPrices)
+		})
+	}
+}
+
+func TestUpdateGridLevelsKeepsExecuted(t *testing.T) {
+	levels := newGridLevelMap()
+	updateGridLevels(levels, 100, 1, 2, 0, 0, 0, nil)
+	level, _ := levels.Get("B1")
+	level.Executed = true
+	levels.Set("B1", level)
+
+	updateGridLevels(levels, 100, 2, 2, 0, 0, 0, nil)
+	level, _ = levels.Get("B1")
+	if !level.Executed {
+		t.Error("B1 lost Executed after update")
+	}
+	assertFloat(t, "B1.Price", level.Price, 98)
+}
+
+func TestMergeNearbyLevels(t *testing.T) {
+	tests := []struct {
+		name       string
+		levels     []GridLevel
+		minSpacing float64
+		wantPrices map[string]float64
+		wantPinned []string
+	}{
+		{
+			name: "far apart levels untouched",
+			levels: []GridLevel{
+				{Name: "B1", Index: 1, Type: LevelBuy, Price: 99},
+				{Name: "B2", Index: 2, Type: LevelBuy, Price: 98},
+			},
+			minSpacing: 0.5,
+			wantPrices: map[string]float64{"B1": 99, "B2": 98},
+		},
+		{
+			name: "close pair merged into lower index",
+			levels: []GridLevel{
+				{Name: "B1", Index: 1, Type: LevelBuy, Price: 99},
+				{Name: "B2", Index: 2, Type: LevelBuy, Price: 98.8},
+			},
+			minSpacing: 0.5,
+			wantPrices: map[string]float64{"B1": 98.9},
+			wantPinned: []string{"B1"},
+		},
+		{
+			name: "executed level not merged",
+			levels: []GridLevel{
+				{Name: "B1", Index: 1, Type: LevelBuy, Price: 99, Executed: true},
+				{Name: "B2", Index: 2, Type: LevelBuy, Price: 98.8},
+			},
+			minSpacing: 0.5,
+			wantPrices: map[string]float64{"B1": 99, "B2": 98.8},
+		},
+		{
+			name: "different types not merged",
+			levels: []GridLevel{
+				{Name: "B1", Index: 1, Type: LevelBuy, Price: 99.9},
+				{Name: "S1", Index: 1, Type: LevelSell, Price: 100.1},
+			},
+			minSpacing: 0.5,
+			wantPrices: map[string]float64{"B1": 99.9, "S1": 100.1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := make(map[string]GridLevel, len(tt.levels))
+			for _, level := range tt.levels {
+				in[level.Name] = level
+			}
+			got := mergeNearbyLevels(in, tt.minSpacing)
+			assertLevelPrices(t, got, tt.wantPrices)
+			for _, name := range tt.wantPinned {
+				if !got[name].Pinned {
+					t.Errorf("%s not pinned after merge", name)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitNearestLevel(t *testing.T) {
+	base := map[string]GridLevel{
+		"B1": {Name: "B1", Index: 1, Type: LevelBuy, Price: 99, Active: true},
+		"B2": {Name: "B2", Index: 2, Type: LevelBuy, Price: 98, Active: true},
+		"S1": {Name: "S1", Index: 1, Type: LevelSell, Price: 101, Active: true},
+	}
+	tests := []struct {
+		name       string
+		levels     map[string]GridLevel
+		price      float64
+		maxPerSide int
+		wantSplit  bool
+		wantPrices map[string]float64
+	}{
+		{
+			name: "nearest buy split", levels: base, price: 99.2, maxPerSide: 4, wantSplit: true,
+			wantPrices: map[string]float64{"B1": 99.5, "B3": 98.5, "B2": 98, "S1": 101},
+		},
+		{
+			name: "side full", levels: base, price: 99.2, maxPerSide: 2,
+			wantPrices: map[string]float64{"B1": 99, "B2": 98, "S1": 101},
+		},
+		{
+			name: "executed level skipped", price: 99.2, maxPerSide: 4, wantSplit: true,
+			levels: map[string]GridLevel{
+				"B1": {Name: "B1", Index: 1, Type: LevelBuy, Price: 99, Active: true, Executed: true},
+				"S1": {Name: "S1", Index: 1, Type: LevelSell, Price: 101, Active: true},
+			},
+			wantPrices: map[string]float64{"B1": 99, "S1": 101.5, "S2": 100.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, split := splitNearestLevel(tt.levels, tt.price, 1, tt.maxPerSide)
+			if split != tt.wantSplit {
+				t.Errorf("split = %v, want %v", split, tt.wantSplit)
+			}
+			assertLevelPrices(t, got, tt.wantPrices)
+		})
+	}
+}
+
+// Bölünen ve birleştirilen seviyeler sonraki updateGridLevels çağrısında geri alınmamalı
+func TestSplitAndMergeSurviveUpdate(t *testing.T) {
+	levels := newGridLevelMap()
+	updateGridLevels(levels, 100, 1, 2, 0, 0, 0, nil)
+	split, ok := splitNearestLevel(levels.Snapshot(), 99.2, 1, 4)
+	if !ok {
+		t.Fatal("split not applied")
+	}
+	levels.Replace(split)
+	updateGridLevels(levels, 100, 1.1, 2, 0, 0, 0, nil)
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{
+		"B1": 99.5, "B3": 98.5, "B2": 97.8, "S1": 101.1, "S2": 102.2,
+	})
+
+	levels = newGridLevelMap()
+	levels.Replace(map[string]GridLevel{
+		"B1": {Name: "B1", Index: 1, Type: LevelBuy, Price: 99, Active: true},
+		"B2": {Name: "B2", Index: 2, Type: LevelBuy, Price: 98.8, Active: true},
+	})
+	before := levels.Snapshot()
+	merged := mergeNearbyLevels(before, 0.5)
+	levels.Replace(merged)
+	for name := range before {
+		if _, ok := merged[name]; !ok {
+			levels.Retire(name)
+		}
+	}
+	updateGridLevels(levels, 100, 1, 2, 0, 0, 0, nil)
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{"B1": 98.9, "S1": 101, "S2": 102})
+
+	levels.ResetLayout()
+	updateGridLevels(levels, 100, 1, 2, 0, 0, 0, nil)
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{"B1": 99, "B2": 98, "S1": 101, "S2": 102})
+}
+
+func TestIsLevelTriggered(t *testing.T) {
+	tests := []struct {
+		name             string
+		level, low, high float64
+		atr, zone        float64
+		want             bool
+	}{
+		{name: "bar crosses level", level: 100, low: 99, high: 101, want: true},
+		{name: "bar above level", level: 100, low: 100.5, high: 101},
+		{name: "bar above level within zone", level: 100, low: 100.5, high: 101, atr: 1, zone: 0.5, want: true},
+		{name: "bar below level outside zone", level: 100, low: 98, high: 99, atr: 1, zone: 0.5},
+		{name: "touch exactly", level: 100, low: 100, high: 100.2, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLevelTriggered(tt.level, tt.low, tt.high, tt.atr, tt.zone); got != tt.want {
+				t.Errorf("isLevelTriggered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapToTick(t *testing.T) {
+	tests := []struct {
+		name        string
+		price, tick float64
+		want        float64
+	}{
+		{name: "no tick", price: 99.123, tick: 0, want: 99.123},
+		{name: "round down", price: 99.123, tick: 0.01, want: 99.12},
+		{name: "round up", price: 99.126, tick: 0.01, want: 99.13},
+		{name: "coarse tick", price: 99.7, tick: 0.5, want: 99.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloat(t, "snapToTick", snapToTick(tt.price, tt.tick), tt.want)
+		})
+	}
+}
+
+func TestATRBands(t *testing.T) {
+	tests := []struct {
+		name      string
+		ema, atr  float64
+		count     int
+		wantBands []float64
+	}{
+		{name: "three bands", ema: 100, atr: 2, count: 3, wantBands: []float64{98, 96, 94}},
+		{name: "NaN indicator", ema: math.NaN(), atr: 2, count: 3},
+		{name: "zero ATR", ema: 100, atr: 0, count: 3},
+		{name: "no bands", ema: 100, atr: 2, count: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := atrBands(tt.ema, tt.atr, tt.count)
+			if len(got) != len(tt.wantBands) {
+				t.Fatalf("got %d bands, want %d", len(got), len(tt.wantBands))
+			}
+			for i := range got {
+				assertFloat(t, "band", got[i], tt.wantBands[i])
+			}
+		})
+	}
+}
+
+func TestComputeStochRSI(t *testing.T) {
+	wave := make([]float64, 300)
+	for i := range wave {
+		wave[i] = 100 + 5*math.Sin(float64(i)/7) + float64(i%3)
+	}
+	rising := make([]float64, 300)
+	for i := range rising {
+		rising[i] = 100 + float64(i)
+	}
+	tests := []struct {
+		name    string
+		closes  []float64
+		wantNaN bool
+		wantK   float64 // NaN ise yalnızca 0-100 aralığı kontrol edilir
+	}{
+		{name: "oscillating series in range", closes: wave, wantK: math.NaN()},
+		{name: "flat RSI is neutral", closes: rising, wantK: 50},
+		{name: "too few bars", closes: wave[:20], wantNaN: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, d := computeStochRSI(newSeries(tt.closes...), 14, 14, 3, 3)
+			if tt.wantNaN {
+				if !math.IsNaN(k) || !math.IsNaN(d) {
+					t.Errorf("got k=%.2f d=%.2f, want NaN", k, d)
+				}
+				return
+			}
+			for _, v := range []float64{k, d} {
+				if math.IsNaN(v) || v < 0 || v > 100 {
+					t.Errorf("value %.4f outside [0, 100]", v)
+				}
+			}
+			if !math.IsNaN(tt.wantK) {
+				assertFloat(t, "k", k, tt.wantK)
+			}
+		})
+	}
+}
+
+func TestBackfillGrid(t *testing.T) {
+	buy := map[string]GridLevel{"B1": {Name: "B1", Type: LevelBuy, Price: 99, Active: true, SizeMultiplier: 1}}
+	sell := map[string]GridLevel{"S1": {Name: "S1", Type: LevelSell, Price: 100, Active: true, SizeMultiplier: 1}}
+	tests := []struct {
+		name         string
+		levels       map[string]GridLevel
+		bars         []backfillBar
+		spacing      float64
+		wantTrades   int
+		wantRealized float64
+		wantOpen     float64
+	}{
+		{
+			name: "buy round trip then re-entry", levels: buy, spacing: 1,
+			bars:       []backfillBar{{High: 100, Low: 98.5, Close: 99}, {High: 100.5, Low: 99, Close: 100}},
+			wantTrades: 1, wantRealized: 1, wantOpen: 1,
+		},
+		{
+			name: "sell round trip then re-entry", levels: sell, spacing: 1,
+			bars:       []backfillBar{{High: 100.5, Low: 99.5, Close: 100}, {High: 100, Low: 98.5, Close: 99}},
+			wantTrades: 1, wantRealized: 1, wantOpen: 1,
+		},
+		{
+			name: "untouched level", levels: buy, spacing: 1,
+			bars: []backfillBar{{High: 101, Low: 100, Close: 100.5}},
+		},
+		{
+			name: "zero spacing", levels: buy,
+			bars: []backfillBar{{High: 100, Low: 98, Close: 99}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backfillGrid(tt.levels, tt.bars, tt.spacing, 100)
+			if got.Trades != tt.wantTrades {
+				t.Errorf("Trades = %d, want %d", got.Trades, tt.wantTrades)
+			}
+			price := 99.0
+			if _, ok := tt.levels["S1"]; ok {
+				price = 100
+			}
+			scale := 100 / price // amount = positionCost / seviye fiyatı
+			assertFloat(t, "RealizedPnL", got.RealizedPnL, tt.wantRealized*scale)
+			assertFloat(t, "OpenPnL", got.OpenPnL, tt.wantOpen*scale)
+		})
+	}
+}
+
+func TestValidateGridConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]interface{}
+		wantErr bool
+	}{
+		{name: "empty", raw: map[string]interface{}{}},
+		{name: "valid types", raw: map[string]interface{}{"enable_grid": true, "activation_mode": 2.0, "tp_ratchet_pct": 0.2, "heatmap_path": "h.csv"}},
+		{name: "unknown parameter", raw: map[string]interface{}{"no_such_param": 1.0}, wantErr: true},
+		{name: "bool as number", raw: map[string]interface{}{"enable_grid": 1.0}, wantErr: true},
+		{name: "fractional int", raw: map[string]interface{}{"activation_mode": 2.5}, wantErr: true},
+		{name: "number as string", raw: map[string]interface{}{"tp_ratchet_pct": "0.2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGridConfig(tt.raw); (err != nil) != tt.wantErr {
+				t.Errorf("validateGridConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRollingReturns(t *testing.T) {
+	tests := []struct {
+		name        string
+		returns     []float64
+		maxLen      int
+		wantLen     int
+		wantSharpe  float64 // barSecs = 86400, faktör sqrt(365)
+		wantSortino float64
+	}{
+		{name: "constant returns", returns: []float64{0.01, 0.01, 0.01}, maxLen: 10, wantLen: 3},
+		{
+			name: "window keeps newest", returns: []float64{-0.5, 0.01, 0.03}, maxLen: 2, wantLen: 2,
+			wantSharpe: 0.02 / math.Sqrt(0.0002) * math.Sqrt(365),
+		},
+		{
+			name: "mixed signs", returns: []float64{0.02, -0.01}, maxLen: 10, wantLen: 2,
+			wantSharpe:  0.005 / math.Sqrt(0.00045) * math.Sqrt(365),
+			wantSortino: 0.005 / math.Sqrt(0.0001/2) * math.Sqrt(365),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRollingReturns(tt.maxLen)
+			for _, ret := range tt.returns {
+				r.Add(ret)
+			}
+			if r.Len() != tt.wantLen {
+				t.Errorf("Len = %d, want %d", r.Len(), tt.wantLen)
+			}
+			assertFloat(t, "Sharpe", r.Sharpe(86400), tt.wantSharpe)
+			assertFloat(t, "Sortino", r.Sortino(86400), tt.wantSortino)
+		})
+	}
+}
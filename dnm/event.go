@@ -0,0 +1,188 @@
+package dnm
+
+import (
+	"math"
+	"sync"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+	ta "github.com/banbox/banta"
+)
+
+// priceFeedKey - priceFeeds anahtarı; aynı sembolde çalışan instance'lar birbirinin tick'lerini tüketmez
+type priceFeedKey struct {
+	symbol     string
+	instanceID string
+}
+
+// priceFeeds - (sembol, instance) -> gerçek zamanlı fiyat olayları kanalı
+var priceFeeds sync.Map
+
+// priceFeedBuffer - bar arasında biriken olay kapasitesi; dolarsa yeni olaylar atılır
+const priceFeedBuffer = 1024
+
+// RegisterPriceFeed - sembol ve instance_id için tick fiyat kanalı açar. Dönen kanala gönderilen fiyatlar
+// o instance'ın EventDrivenGrid'i tarafından sonraki OnBar'da sırayla işlenir.
+func RegisterPriceFeed(symbol, instanceID string) chan<- float64 {
+	val, _ := priceFeeds.LoadOrStore(priceFeedKey{symbol, instanceID}, make(chan float64, priceFeedBuffer))
+	return val.(chan float64)
+}
+
+// drainPriceFeed - sembol ve instance için biriken tick fiyatlarını bloklamadan okur
+func drainPriceFeed(symbol, instanceID string) []float64 {
+	val, ok := priceFeeds.Load(priceFeedKey{symbol, instanceID})
+	if !ok {
+		return nil
+	}
+	ch := val.(chan float64)
+	var ticks []float64
+	for {
+		select {
+		case price := <-ch:
+			ticks = append(ticks, price)
+		default:
+			return ticks
+		}
+	}
+}
+
+// simTicks - OHLC barı n sentetik tick'e böler. Yükselen barda open→low→high→close,
+// düşen barda open→high→low→close yolu izlenir ve yol uzunluğu boyunca eşit aralıkla örneklenir.
+func simTicks(open, high, low, close float64, n int) []float64 {
+	path := []float64{open, low, high, close}
+	if close < open {
+		path = []float64{open, high, low, close}
+	}
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		total += math.Abs(path[i] - path[i-1])
+	}
+	if n < 1 || total == 0 {
+		return []float64{close}
+	}
+	ticks := make([]float64, 0, n)
+	seg, walked := 1, 0.0
+	for k := 1; k <= n; k++ {
+		target := total * float64(k) / float64(n)
+		for seg < len(path)-1 && walked+math.Abs(path[seg]-path[seg-1]) < target {
+			walked += math.Abs(path[seg] - path[seg-1])
+			seg++
+		}
+		from, to := path[seg-1], path[seg]
+		if length := math.Abs(to - from); length > 0 {
+			ticks = append(ticks, from+(to-from)*math.Min((target-walked)/length, 1))
+		} else {
+			ticks = append(ticks, to)
+		}
+	}
+	return ticks
+}
+
+// EventDrivenGrid - seviyeleri bar kapanışı yerine tick fiyat olaylarıyla tetikleyen sabit aralıklı grid.
+// Canlıda RegisterPriceFeed ile beslenen tick'ler kullanılır; tick gelmediyse ya da backtest'te bar
+// sim_ticks_per_bar sentetik tick'e bölünür. Tetiklenen seviye, geçildiği fiyattan limit emirle açılır.
+func EventDrivenGrid(pol *config.RunPolicyConfig) *strat.TradeStrat {
+
+	instanceID := string(pol.Def("instance_id", "event"))
+	baseGridCount := int(pol.Def("base_grid_count", 8, core.PNorm(3, 15)))
+	baseSpacingPct := float64(pol.Def("base_spacing_pct", 1.0, core.PNorm(0.2, 3.0)))
+	atrPeriod := int(pol.Def("atr_period", 14, core.PNorm(5, 50)))
+	tickSize := float64(pol.Def("tick_size", 0.0001))
+	simTicksPerBar := int(pol.Def("sim_ticks_per_bar", 4, core.PNorm(2, 20)))
+
+	// Risk Management
+	initialCapital := float64(pol.Def("initial_capital", 10000.0))
+	maxSinglePosition := float64(pol.Def("max_single_position", 5.0, core.PNorm(1.0, 10.0)))
+	maxConcurrentTrades := int(pol.Def("max_concurrent_trades", 16, core.PNorm(4, 30)))
+	stopLossATR := float64(pol.Def("stop_loss_atr", 2.0, core.PNorm(1.0, 5.0)))
+	takeProfitATR := float64(pol.Def("take_profit_atr", 3.0, core.PNorm(1.5, 8.0)))
+
+	gridCount := min(baseGridCount, maxGridLevels)
+	levels := newGridLevelMap()
+	var gridBasePrice float64 = 0
+	var lastTick float64 = 0
+	var totalRealizedPnl float64 = 0
+
+	return &strat.TradeStrat{
+		WarmupNum:     gridWarmupNum,
+		StopEnterBars: validateStopEnterBars(pol),
+
+		OnBar: func(s *strat.StratJob) {
+			e := s.Env
+
+			if e.Close.Len() < atrPeriod || e.High.Len() < atrPeriod || e.Low.Len() < atrPeriod {
+				return
+			}
+			if !gridWarmedUp(e.Close.Len()) {
+				return // base warmup bitmeden sabitlenmez
+			}
+			currentPrice := e.Close.Last(0)
+			atrValue := ta.ATR(e.High, e.Low, e.Close, atrPeriod)
+			if math.IsNaN(atrValue) {
+				return
+			}
+			if gridBasePrice == 0 {
+				gridBasePrice = currentPrice
+				lastTick = currentPrice
+				s.Infof("Event grid initialized at price: %.4f", gridBasePrice)
+			}
+			updateGridLevels(levels, gridBasePrice, currentPrice*baseSpacingPct/100, gridCount, tickSize, 0, 0, nil)
+
+			var ticks []float64
+			if !core.BacktestMode {
+				ticks = drainPriceFeed(s.Symbol, instanceID)
+			}
+			if len(ticks) == 0 {
+				ticks = simTicks(e.Open.Last(0), e.High.Last(0), e.Low.Last(0), currentPrice, simTicksPerBar)
+			}
+
+			openTrades := len(GridOpenOrders(s, gridTagPrefix(instanceID)))
+			size := initialCapital * (maxSinglePosition / 100) / float64(baseGridCount)
+			for _, tick := range ticks {
+				for i := 1; i <= gridCount; i++ {
+					for _, levelType := range []string{LevelBuy, LevelSell} {
+						name := levelName(levelType, i)
+						level, ok := levels.Get(name)
+						if !ok || !level.Active || level.Executed || openTrades >= maxConcurrentTrades {
+							continue
+						}
+						crossed := lastTick > level.Price && tick <= level.Price
+						if levelType == LevelSell {
+							crossed = lastTick < level.Price && tick >= level.Price
+						}
+						if !crossed {
+							continue
+						}
+						req := &strat.EnterReq{
+							Tag:    levelTag(instanceID, levelType, i),
+							Short:  levelType == LevelSell,
+							Amount: size * level.SizeMultiplier,
+							Limit:  level.Price,
+						}
+						if err := s.OpenOrder(req); err != nil {
+							s.Infof("Event grid %s level %d not executed: %v", levelType, i, err)
+							continue
+						}
+						level.Executed = true
+						levels.Set(name, level)
+						openTrades++
+						s.Infof("Event grid %s level %d crossed at tick %.4f", levelType, i, tick)
+					}
+				}
+				lastTick = tick
+			}
+
+			closed := manageTradingOrders(s, levels, instanceID, atrValue, stopLossATR, takeProfitATR, TPTypeATR, 0, false, nil, nil)
+			for _, trade := range closed {
+				totalRealizedPnl += trade.PnL
+			}
+			releaseClosedLevels(s, levels, instanceID, closed) // kapanan seviye sonraki geçişte yeniden tetiklenir
+
+			if e.BarIndex%100 == 0 {
+				s.Infof("Event Grid: Price=%.4f, Base=%.4f, Ticks=%d, Open=%d, PnL=%.2f",
+					currentPrice, gridBasePrice, len(ticks), openTrades, totalRealizedPnl)
+			}
+		},
+	}
+}
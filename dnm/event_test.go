@@ -0,0 +1,32 @@
+package dnm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Aynı sembolde iki instance: her biri yalnızca kendi kanalına gönderilen tick'leri okur
+func TestPriceFeedPerInstance(t *testing.T) {
+	const symbol = "ETH/USDT:USDT"
+	feedA := RegisterPriceFeed(symbol, "A")
+	feedB := RegisterPriceFeed(symbol, "B")
+	if RegisterPriceFeed(symbol, "A") != feedA {
+		t.Error("second registration returned a new channel")
+	}
+	feedA <- 100
+	feedA <- 101
+	feedB <- 200
+
+	if got := drainPriceFeed(symbol, "B"); !reflect.DeepEqual(got, []float64{200}) {
+		t.Errorf("instance B ticks = %v, want [200]", got)
+	}
+	if got := drainPriceFeed(symbol, "A"); !reflect.DeepEqual(got, []float64{100, 101}) {
+		t.Errorf("instance A ticks = %v, want [100 101]", got)
+	}
+	if got := drainPriceFeed(symbol, "A"); len(got) != 0 {
+		t.Errorf("drained feed returned %v again", got)
+	}
+	if got := drainPriceFeed(symbol, "C"); got != nil {
+		t.Errorf("unregistered instance got %v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package dnm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisClient - exporter'ın kullandığı tek Redis komutu; testlerde sahte istemciyle değiştirilebilir
+type redisClient interface {
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// respClient - yalnızca SET destekleyen, tek bağlantılı minimal RESP istemcisi.
+// Bağlantı ilk kullanımda açılır, hata durumunda kapatılıp sonraki çağrıda yeniden kurulur.
+type respClient struct {
+	addr string
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func (c *respClient) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		conn, err := net.DialTimeout("tcp", c.addr, 2*time.Second)
+		if err != nil {
+			return fmt.Errorf("dial redis %s: %w", c.addr, err)
+		}
+		c.conn, c.rd = conn, bufio.NewReader(conn)
+	}
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_ = c.conn.SetDeadline(time.Now().Add(2 * time.Second))
+	reply, err := c.roundTrip(b.String())
+	if err != nil {
+		c.conn.Close()
+		c.conn, c.rd = nil, nil
+		return err
+	}
+	if strings.HasPrefix(reply, "-") {
+		return fmt.Errorf("redis SET %s: %s", key, strings.TrimPrefix(reply, "-"))
+	}
+	return nil
+}
+
+func (c *respClient) roundTrip(cmd string) (string, error) {
+	if _, err := c.conn.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// RedisExporter - grid durumunu dashboard'lar için Redis'e yazar
+type RedisExporter struct {
+	client redisClient
+}
+
+func NewRedisExporter(addr string) *RedisExporter {
+	return &RedisExporter{client: &respClient{addr: addr}}
+}
+
+// gridExport - Redis'e yazılan JSON
+type gridExport struct {
+	Stats         GridStats         `json:"stats"`
+	Visualization GridVisualization `json:"visualization"`
+}
+
+// Export - istatistik ve seviye görüntüsünü key altına ttl süreyle yazar
+func (e *RedisExporter) Export(key string, stats GridStats, viz GridVisualization, ttl time.Duration) error {
+	data, err := json.Marshal(gridExport{Stats: stats, Visualization: viz})
+	if err != nil {
+		return err
+	}
+	return e.client.Set(key, data, ttl)
+}
+
+// gridExportKey - "grid:{symbol}:{timeframe}"
+func gridExportKey(symbol, timeFrame string) string {
+	return fmt.Sprintf("grid:%s:%s", symbol, timeFrame)
+}
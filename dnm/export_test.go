@@ -0,0 +1,90 @@
+package dnm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockRedis - SET çağrılarını kaydeden sahte istemci
+type mockRedis struct {
+	keys map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func (m *mockRedis) Set(key string, value []byte, ttl time.Duration) error {
+	m.keys[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func TestRedisExporterExport(t *testing.T) {
+	mock := &mockRedis{keys: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	exporter := &RedisExporter{client: mock}
+	key := gridExportKey("BTC/USDT", "1h")
+	if key != "grid:BTC/USDT:1h" {
+		t.Errorf("key = %s", key)
+	}
+	stats := GridStats{MergeCount: 2, Sharpe: 1.5}
+	viz := GridVisualization{BasePrice: 100, CurrentPrice: 101, Levels: []LevelViz{{Price: 99, Type: LevelBuy}}}
+	if err := exporter.Export(key, stats, viz, 3*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if mock.ttls[key] != 3*time.Hour {
+		t.Errorf("ttl = %s, want 3h", mock.ttls[key])
+	}
+	var got gridExport
+	if err := json.Unmarshal(mock.keys[key], &got); err != nil {
+		t.Fatalf("exported value is not JSON: %v", err)
+	}
+	if got.Stats.MergeCount != 2 || got.Visualization.BasePrice != 100 || len(got.Visualization.Levels) != 1 {
+		t.Errorf("exported %+v", got)
+	}
+}
+
+// respClient'ın gönderdiği RESP komutunu sahte bir sunucuyla doğrular
+func TestRespClientSet(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rd := bufio.NewReader(conn)
+		fmt.Fscanf(rd, "*%d\r\n", new(int))
+		var args []string
+		for i := 0; i < 5; i++ {
+			var n int
+			if _, err := fmt.Fscanf(rd, "$%d\r\n", &n); err != nil {
+				return
+			}
+			buf := make([]byte, n+2)
+			if _, err := io.ReadFull(rd, buf); err != nil {
+				return
+			}
+			args = append(args, string(buf[:n]))
+		}
+		received <- args
+		io.WriteString(conn, "+OK\r\n")
+	}()
+
+	client := &respClient{addr: ln.Addr().String()}
+	if err := client.Set("grid:ETH:1m", []byte(`{"a":1}`), 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	args := <-received
+	if got := strings.Join(args, " "); got != `SET grid:ETH:1m {"a":1} PX 1500` {
+		t.Errorf("server received %q", got)
+	}
+}
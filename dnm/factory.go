@@ -0,0 +1,177 @@
+package dnm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/strat"
+)
+
+// gridStrategies - NewGridStrategy ile oluşturulabilen grid stratejileri
+var gridStrategies = map[string]strat.FuncMakeStrat{
+	"grid_pro":         GridPro,
+	"grid_multi":       MultiLayerGrid,
+	"grid_conditional": makeConditionalGrid,
+	"grid_event":       EventDrivenGrid,
+	"grid_arb":         ArbitrageGrid,
+}
+
+// gridProGroup - "gridpro" strateji grubundaki adlar -> gridStrategies anahtarı.
+// Burada olmayan grid stratejileri "ma" grubuna kaydedilir.
+var gridProGroup = map[string]string{
+	"multi":       "grid_multi",
+	"conditional": "grid_conditional",
+	"event":       "grid_event",
+	"arb":         "grid_arb",
+}
+
+// GridConfig - GridPro instance'ına özel hook'lar. Paket düzeyinde değişken yerine instance başına
+// tutulduğundan aynı süreçte çalışan job'lar birbirinin hook'unu ezmez. nil alan varsayılan davranışı seçer.
+type GridConfig struct {
+	// OnCapitalShortfall - grid emri sermaye yetersizliğinden reddedildiğinde, tekrar denemeden önce çağrılır.
+	// nil ise eksik loglanır; portföy yöneticisine sermaye talebi iletmek için kullanılabilir.
+	OnCapitalShortfall func(s *strat.StratJob, req CapitalRequest)
+
+	// PreGridCheck - grid kurulmadan önce çağrılır (API bağlantısı, likidite, bakiye kontrolü vb.).
+	// Hata dönerse kurulum bir sonraki bara ertelenir. nil ise kontrol yapılmaz.
+	PreGridCheck func(s *strat.StratJob) error
+
+	// OnDriftDetected - dolumlar driftIntervals durum aralığı boyunca tek tarafta biriktiğinde bir kez çağrılır.
+	// true dönerse grid güncel fiyata taşınır (rebalance bekleme süresine tabidir).
+	OnDriftDetected func(s *strat.StratJob, drift DriftMetrics) bool
+
+	// ArbQuoteSource - ArbitrageGrid için canlıda exchange_b'nin güncel fiyatı. banbot job'u yalnızca kendi
+	// borsasının verisini gördüğünden ikinci borsanın fiyatı dışarıdan sağlanmalıdır; nil ise canlıda işlem açılmaz.
+	ArbQuoteSource func(exchange, symbol string) (float64, bool)
+
+	// OnArbitrageLeg - ArbitrageGrid'in exchange_b bacağını açar/kapatır. Job'un emir motoru tek borsaya bağlı
+	// olduğundan bacak bu hook'a devredilir. nil ise backtest'te bacak loglanıp dolmuş sayılır,
+	// canlıda ise hedge edilmemiş pozisyon açılmaması için işlem yapılmaz.
+	OnArbitrageLeg func(s *strat.StratJob, leg ArbitrageLeg) error
+
+	// Storage - persist_state açıkken state_dir verilmemişse grid durumunun saklandığı yer (SQLite, Redis vb.).
+	// nil ise InMemoryGridStorage kullanılır.
+	Storage GridStorage
+}
+
+// Grid modları (grid_mode)
+const (
+	GridModeFixed   = "Fixed Spacing"
+	GridModeATR     = "ATR Based"
+	GridModeEvenOdd = "Even Odd" // çift index alış, tek index satış; iki taraf da base'in iki yanında
+	GridModeATRBand = "ATR Band" // alışlar EMA - i*ATR bandında, kapanış bandın altına inince; yalnızca long
+)
+
+// Desteklenen grid_mode değerleri
+var gridModes = map[string]bool{
+	GridModeFixed:   true,
+	GridModeATR:     true,
+	GridModeEvenOdd: true,
+	GridModeATRBand: true,
+}
+
+// NewGridStrategy - grid stratejileri için tek giriş noktası.
+// Bilinmeyen tip, eksik/geçersiz parametre ya da config dosyası hatasında error döner.
+func NewGridStrategy(gridType string, pol *config.RunPolicyConfig) (*strat.TradeStrat, error) {
+	makeStrat, ok := gridStrategies[gridType]
+	if !ok {
+		names := make([]string, 0, len(gridStrategies))
+		for name := range gridStrategies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown grid strategy %q, available: %v", gridType, names)
+	}
+	if configFile := string(pol.Def("config_file", "")); configFile != "" {
+		if err := loadGridConfigFromFile(configFile, pol); err != nil {
+			return nil, err
+		}
+		markConfigApplied(pol)
+	}
+	defer takeConfigApplied(pol) // dosyayı okumayan stratejilerde işaret kalmasın
+	if err := validateGridPolicy(pol); err != nil {
+		return nil, fmt.Errorf("grid strategy %s: %w", gridType, err)
+	}
+	return makeStrat(pol), nil
+}
+
+// validateGridPolicy - stratejiler arası ortak parametre kontrolleri
+func validateGridPolicy(pol *config.RunPolicyConfig) error {
+	if mode := string(pol.Def("grid_mode", "Fixed Spacing")); !gridModes[mode] {
+		return fmt.Errorf("unsupported grid_mode %q", mode)
+	}
+	if count := int(pol.Def("base_grid_count", 8)); count < 1 {
+		return fmt.Errorf("base_grid_count must be at least 1, got %d", count)
+	}
+	if period := int(pol.Def("atr_period", 14)); period < 1 {
+		return fmt.Errorf("atr_period must be at least 1, got %d", period)
+	}
+	if capital := float64(pol.Def("initial_capital", 10000.0)); capital <= 0 {
+		return fmt.Errorf("initial_capital must be positive, got %.2f", capital)
+	}
+	if output := string(pol.Def("vis_output", "")); output != "" && int(pol.Def("vis_interval_bars", 10)) <= 0 {
+		return fmt.Errorf("vis_output requires a positive vis_interval_bars")
+	}
+	return validateExclusiveParams(pol)
+}
+
+// validateExclusiveParams - birlikte açıldığında biri diğerini sessizce devre dışı bırakan parametreler
+func validateExclusiveParams(pol *config.RunPolicyConfig) error {
+	mode := string(pol.Def("grid_mode", "Fixed Spacing"))
+	// Mirror grid seviyelerini eş sembolün planından alır; Even Odd ve ATR Band düzenleri uygulanmaz
+	if mirror := string(pol.Def("mirror_symbol", "")); mirror != "" && (mode == GridModeEvenOdd || mode == GridModeATRBand) {
+		return fmt.Errorf("mirror_symbol %q cannot be combined with grid_mode %q", mirror, mode)
+	}
+	// Band modunda tetikleme kapanışın banda inmesidir, dinamik bölge ve kapanış teyidi kullanılmaz
+	if mode == GridModeATRBand && bool(pol.Def("dynamic_spacing", false)) {
+		return fmt.Errorf("dynamic_spacing cannot be combined with grid_mode %q", mode)
+	}
+	if mode == GridModeATRBand && bool(pol.Def("require_close_confirm", false)) {
+		return fmt.Errorf("require_close_confirm cannot be combined with grid_mode %q", mode)
+	}
+	return nil
+}
+
+// noStopEnterBars - dolmamış giriş emirleri süresiz bekler
+const noStopEnterBars = 999999
+
+// validateStopEnterBars - TradeStrat.StopEnterBars değeri. Grid emirleri seviye tetiklenene kadar
+// beklediğinden varsayılan sınırsızdır; expected_bars_per_level verilirse
+// base_grid_count * expected_bars_per_level bar sonra dolmamış emirler iptal edilir.
+func validateStopEnterBars(pol *config.RunPolicyConfig) int {
+	barsPerLevel := int(pol.Def("expected_bars_per_level", 0))
+	if barsPerLevel <= 0 {
+		return noStopEnterBars
+	}
+	return int(pol.Def("base_grid_count", 8)) * barsPerLevel
+}
+
+// makeGridStrategy - strateji grubuna kayıt için NewGridStrategy sarmalayıcısı.
+// Geçersiz config süreci durdurmaz: hata loglanır ve emir açmayan bir strateji döner.
+func makeGridStrategy(gridType string) strat.FuncMakeStrat {
+	return func(pol *config.RunPolicyConfig) *strat.TradeStrat {
+		res, err := NewGridStrategy(gridType, pol)
+		if err != nil {
+			log.Printf("%s: strategy disabled: %v", gridType, err)
+			return disabledGridStrategy(gridType, err)
+		}
+		return res
+	}
+}
+
+// disabledGridStrategy - config hatası nedeniyle çalışmayan strateji; hatayı ilk barda job loguna da yazar
+func disabledGridStrategy(gridType string, err error) *strat.TradeStrat {
+	var once sync.Once
+	return &strat.TradeStrat{
+		WarmupNum:     gridWarmupNum,
+		StopEnterBars: noStopEnterBars,
+		OnBar: func(s *strat.StratJob) {
+			once.Do(func() {
+				s.Infof("%s disabled, no orders will be placed: %v", gridType, err)
+			})
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package dnm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// runHealthCheck - grid durumundaki tutarsızlıkları bulur ve onarır:
+//   - seviyesi olmayan ya da Executed işaretlenmemiş açık emirler seviyeyle yeniden eşlenir
+//     (Active'e dokunulmaz; bilerek pasifleştirilmiş seviye, emri kapanana kadar pasif kalır)
+//   - açık emri kalmamış Executed seviyeler sıfırlanır
+//   - fiyatı NaN olan seviyeler silinir (sonraki güncellemede yeniden oluşturulur)
+//   - fiyat en dış seviyenin 3 aralık ötesine kaydıysa recenter true döner, çağıran base'i taşır
+//
+// Bulunan ve onarılan sorunların açıklamalarını döndürür.
+func runHealthCheck(s *strat.StratJob, gridBase, currentPrice float64, levels *GridLevelMap,
+	instanceID string) (issues []string, recenter bool) {
+	levels.Range(func(name string, level GridLevel) bool {
+		if math.IsNaN(level.Price) {
+			levels.Delete(name)
+			issues = append(issues, fmt.Sprintf("level %s had NaN price, removed", name))
+		}
+		return true
+	})
+
+	// Açık emirleri seviyelerle eşle
+	matched := make(map[string]bool)
+	for _, order := range GridOpenOrders(s, gridTagPrefix(instanceID)) {
+		name, ok := levelNameFromTag(order.Tag, instanceID)
+		if !ok {
+			continue
+		}
+		matched[name] = true
+		level, ok := levels.Get(name)
+		switch {
+		case !ok:
+			level, ok = levelFromName(name)
+			if !ok {
+				continue
+			}
+			level.Price = order.AvgPrice
+			level.Executed = true
+			levels.Set(name, level)
+			issues = append(issues, fmt.Sprintf("orphaned order %s re-matched to new level %s", order.Tag, name))
+		case !level.Executed:
+			level.Executed = true
+			levels.Set(name, level)
+			issues = append(issues, fmt.Sprintf("order %s re-matched to level %s", order.Tag, name))
+		}
+	}
+	levels.Range(func(name string, level GridLevel) bool {
+		if level.Executed && !matched[name] {
+			level.Executed = false
+			levels.Set(name, level)
+			issues = append(issues, fmt.Sprintf("level %s executed without open order, reset", name))
+		}
+		return true
+	})
+
+	// Base kayması: fiyat grid'in tamamen dışına çıkmış
+	var span, maxGap float64
+	list := levels.sorted()
+	for i, level := range list {
+		span = math.Max(span, math.Abs(level.Price-gridBase))
+		if i > 0 {
+			maxGap = math.Max(maxGap, level.Price-list[i-1].Price)
+		}
+	}
+	if maxGap > 0 && math.Abs(currentPrice-gridBase) > span+3*maxGap {
+		recenter = true
+		issues = append(issues, fmt.Sprintf("base %.4f drifted from price %.4f beyond 3x max spacing %.4f, recenter needed",
+			gridBase, currentPrice, maxGap))
+	}
+
+	for _, issue := range issues {
+		s.Infof("Grid health warning: %s", issue)
+	}
+	return issues, recenter
+}
+
+// levelFromName - "B3"/"S2" gibi seviye adından fiyatsız, aktif bir seviye oluşturur
+func levelFromName(name string) (GridLevel, bool) {
+	if len(name) < 2 {
+		return GridLevel{}, false
+	}
+	index, err := strconv.Atoi(name[1:])
+	if err != nil || index < 1 {
+		return GridLevel{}, false
+	}
+	levelType := LevelSell
+	if name[0] == 'B' {
+		levelType = LevelBuy
+	}
+	if levelName(levelType, index) != name {
+		return GridLevel{}, false
+	}
+	return GridLevel{Name: name, Index: index, Type: levelType, Active: true, SizeMultiplier: 1.0}, true
+}
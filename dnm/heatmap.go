@@ -0,0 +1,132 @@
+package dnm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// PriceBinMap - fiyatın sabit genişlikli aralıklarda (bin) kaç kez görüldüğünü sayar.
+// Hacim profili olmadan değer alanlarını deneysel olarak bulmak için kullanılır.
+type PriceBinMap struct {
+	mu      sync.Mutex
+	binSize float64
+	bins    map[int]int
+}
+
+// BinEntry - bir bin'in orta fiyatı ve ziyaret sayısı
+type BinEntry struct {
+	Price float64
+	Count int
+}
+
+func NewPriceBinMap(binSize float64) *PriceBinMap {
+	return &PriceBinMap{binSize: binSize, bins: make(map[int]int)}
+}
+
+// Record - fiyatın düştüğü bin'in sayacını artırır
+func (m *PriceBinMap) Record(price float64) {
+	if m.binSize <= 0 || math.IsNaN(price) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bins[int(math.Floor(price/m.binSize))]++
+}
+
+// TopN - en çok ziyaret edilen n bin, sayıya göre azalan sırada
+func (m *PriceBinMap) TopN(n int) []BinEntry {
+	m.mu.Lock()
+	entries := make([]BinEntry, 0, len(m.bins))
+	for bin, count := range m.bins {
+		entries = append(entries, BinEntry{Price: (float64(bin) + 0.5) * m.binSize, Count: count})
+	}
+	m.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count == entries[j].Count {
+			return entries[i].Price < entries[j].Price
+		}
+		return entries[i].Count > entries[j].Count
+	})
+	if n < len(entries) {
+		entries = entries[:max(n, 0)]
+	}
+	return entries
+}
+
+// heatmapRangePct - zaman×fiyat matrisinin ilk fiyatın iki yanına yayıldığı yüzde; dışarıdaki
+// fiyatlar kenar bin'lere yazılır
+const heatmapRangePct = 25.0
+
+// HeatmapExporter - gerçekleşen grid emirlerini zaman×fiyat matrisinde sayar.
+// Satırlar barsPerBin barlık zaman dilimleri, sütunlar binSize genişliğinde fiyat aralıklarıdır;
+// grid'in teoride değil fiilen nerede çalıştığını görmek için CSV'ye yazılır.
+type HeatmapExporter struct {
+	mu         sync.Mutex
+	priceMin   float64
+	priceMax   float64
+	binSize    float64
+	barsPerBin int
+	timeBins   int
+	matrix     [][]int
+}
+
+func NewHeatmapExporter(priceMin, priceMax, binSize float64, barsPerBin int) *HeatmapExporter {
+	return &HeatmapExporter{priceMin: priceMin, priceMax: priceMax, binSize: binSize, barsPerBin: max(barsPerBin, 1)}
+}
+
+// priceBins - fiyat sütunu sayısı
+func (h *HeatmapExporter) priceBins() int {
+	if h.binSize <= 0 || h.priceMax <= h.priceMin {
+		return 0
+	}
+	return int(math.Ceil((h.priceMax - h.priceMin) / h.binSize))
+}
+
+// Record - barIndex'teki emir fiyatının hücresini artırır; matris yeni zaman dilimleri için büyür
+func (h *HeatmapExporter) Record(barIndex int, price float64) {
+	cols := h.priceBins()
+	if cols == 0 || barIndex < 0 || math.IsNaN(price) {
+		return
+	}
+	col := min(max(int(math.Floor((price-h.priceMin)/h.binSize)), 0), cols-1)
+	row := barIndex / h.barsPerBin
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for len(h.matrix) <= row {
+		h.matrix = append(h.matrix, make([]int, cols))
+	}
+	h.timeBins = len(h.matrix)
+	h.matrix[row][col]++
+}
+
+// WriteCSV - matrisi CSV olarak yazar. İlk satır fiyat bin'lerinin orta fiyatları,
+// sonraki satırlar zaman diliminin ilk bar index'i ve hücre sayılarıdır.
+func (h *HeatmapExporter) WriteCSV(path string) error {
+	cols := h.priceBins()
+	header := make([]string, 0, cols+1)
+	header = append(header, "bar_index")
+	for i := 0; i < cols; i++ {
+		header = append(header, strconv.FormatFloat(h.priceMin+(float64(i)+0.5)*h.binSize, 'f', -1, 64))
+	}
+	records := [][]string{header}
+	h.mu.Lock()
+	for row := 0; row < h.timeBins; row++ {
+		record := make([]string, 0, cols+1)
+		record = append(record, strconv.Itoa(row*h.barsPerBin))
+		for _, count := range h.matrix[row] {
+			record = append(record, strconv.Itoa(count))
+		}
+		records = append(records, record)
+	}
+	h.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
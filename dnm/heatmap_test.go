@@ -0,0 +1,94 @@
+package dnm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeatmapExporter(t *testing.T) {
+	h := NewHeatmapExporter(100, 110, 2, 2)
+	fills := []struct {
+		bar   int
+		price float64
+	}{
+		{bar: 0, price: 101},
+		{bar: 0, price: 103},
+		{bar: 1, price: 101},
+		{bar: 2, price: 109},
+		{bar: 3, price: 150}, // aralık dışı, son sütuna yazılır
+		{bar: 4, price: 95},  // aralık dışı, ilk sütuna yazılır
+	}
+	for _, fill := range fills {
+		h.Record(fill.bar, fill.price)
+	}
+	want := [][]int{
+		{2, 1, 0, 0, 0},
+		{0, 0, 0, 0, 2},
+		{1, 0, 0, 0, 0},
+	}
+	if len(h.matrix) != len(want) {
+		t.Fatalf("got %d time bins, want %d", len(h.matrix), len(want))
+	}
+	for row := range want {
+		for col := range want[row] {
+			if h.matrix[row][col] != want[row][col] {
+				t.Errorf("matrix[%d][%d] = %d, want %d", row, col, h.matrix[row][col], want[row][col])
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "heatmap.csv")
+	if err := h.WriteCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "bar_index,101,103,105,107,109\n0,2,1,0,0,0\n2,0,0,0,0,2\n4,1,0,0,0,0\n"
+	if string(data) != wantCSV {
+		t.Errorf("csv =\n%s\nwant\n%s", data, wantCSV)
+	}
+}
+
+func TestPriceBinMapTopN(t *testing.T) {
+	m := NewPriceBinMap(1)
+	for _, price := range []float64{100.2, 100.7, 101.5, 101.1, 101.9, 99.4} {
+		m.Record(price)
+	}
+	tests := []struct {
+		n          int
+		wantPrices []float64
+	}{
+		{n: -1},
+		{n: 0},
+		{n: 2, wantPrices: []float64{101.5, 100.5}},
+		{n: 10, wantPrices: []float64{101.5, 100.5, 99.5}},
+	}
+	for _, tt := range tests {
+		got := m.TopN(tt.n)
+		if len(got) != len(tt.wantPrices) {
+			t.Errorf("TopN(%d) = %v, want %d entries", tt.n, got, len(tt.wantPrices))
+			continue
+		}
+		for i, price := range tt.wantPrices {
+			assertFloat(t, "TopN price", got[i].Price, price)
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != content {
+			t.Errorf("file = %q (%v), want %q", data, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package dnm
+
+import (
+	"math"
+	"math/cmplx"
+
+	ta "github.com/banbox/banta"
+)
+
+// Trend göstergesi seçenekleri (trend_indicator)
+const (
+	TrendIndicatorEMA        = 1
+	TrendIndicatorSupertrend = 2
+)
+
+// detectEMACross - hızlı EMA'nın yavaş EMA'yı bir önceki bardan bu bara kesip kesmediği.
+// goldenCross yukarı, deathCross aşağı kesişimdir.
+func detectEMACross(fastPrev, fastCurr, slowPrev, slowCurr float64) (goldenCross, deathCross bool) {
+	wasAbove := fastPrev > slowPrev
+	isAbove := fastCurr > slowCurr
+	return !wasAbove && isAbove, wasAbove && !isAbove
+}
+
+// computeATRBands - EMA'nın altında ATR aralıklı bandCount alış bandı: bands[i-1] = EMA - i*ATR.
+// Göstergeler henüz hesaplanamıyorsa nil döner.
+func computeATRBands(close, high, low *ta.Series, emaPeriod, atrPeriod, bandCount int) []float64 {
+	return atrBands(ta.EMA(close, emaPeriod), ta.ATR(high, low, close, atrPeriod), bandCount)
+}
+
+// atrBands - verilen EMA ve ATR değerlerinden computeATRBands bantları
+func atrBands(ema, atr float64, bandCount int) []float64 {
+	if math.IsNaN(ema) || math.IsNaN(atr) || atr <= 0 || bandCount < 1 {
+		return nil
+	}
+	bands := make([]float64, bandCount)
+	for i := range bands {
+		bands[i] = ema - float64(i+1)*atr
+	}
+	return bands
+}
+
+// computeDominantPeriod - son lookback kapanışın ortalaması çıkarılmış serisinde ayrık Fourier
+// dönüşümüyle en güçlü salınımın periyodu (bar). Periyot 2..lookback/2 aralığında aranır;
+// veri yetersizse ya da seri düzse 0 döner.
+func computeDominantPeriod(close *ta.Series, lookback int) int {
+	values := seriesWindow(close, lookback)
+	n := len(values)
+	if n < 8 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+
+	bestK, bestPower := 0, 0.0
+	// k = 1 tüm pencereye yayılan trend bileşeni, dönem sayılmaz
+	for k := 2; k <= n/2; k++ {
+		var sum complex128
+		for t, v := range values {
+			sum += complex(v-mean, 0) * cmplx.Exp(complex(0, -2*math.Pi*float64(k*t)/float64(n)))
+		}
+		if power := cmplx.Abs(sum); power > bestPower {
+			bestK, bestPower = k, power
+		}
+	}
+	if bestK == 0 {
+		return 0
+	}
+	return int(math.Round(float64(n) / float64(bestK)))
+}
+
+// computeCumulativeDelta - son lookback barın tahmini alış - satış hacmi toplamı. Bar içi dağılım
+// bilinmediğinden alış payı kapanışın bar aralığındaki konumu ((close-low)/(high-low)) kabul edilir;
+// aralığı sıfır olan barda yön kapanışın açılışa göre konumundan alınır.
+func computeCumulativeDelta(close, open, high, low, volume *ta.Series, lookback int) float64 {
+	n := min(lookback, close.Len(), open.Len(), high.Len(), low.Len(), volume.Len())
+	delta := 0.0
+	for i := 0; i < n; i++ {
+		c, o, h, l, v := close.Last(i), open.Last(i), high.Last(i), low.Last(i), volume.Last(i)
+		if math.IsNaN(v) {
+			continue
+		}
+		if h > l {
+			delta += v * (2*c - h - l) / (h - l) // alış payı - satış payı
+		} else if c > o {
+			delta += v
+		} else if c < o {
+			delta -= v
+		}
+	}
+	return delta
+}
+
+// isDeltaConfirmed - alış seviyesi için pozitif, satış seviyesi için negatif delta gerekir
+func isDeltaConfirmed(delta float64, orderType string) bool {
+	if orderType == LevelSell {
+		return delta < 0
+	}
+	return delta > 0
+}
+
+// seriesWindow - serinin son n değerini eskiden yeniye sıralı döndürür
+func seriesWindow(s *ta.Series, n int) []float64 {
+	if n > s.Len() {
+		n = s.Len()
+	}
+	res := make([]float64, n)
+	for i := 0; i < n; i++ {
+		res[n-1-i] = s.Last(i)
+	}
+	return res
+}
+
+// computeSupertrend - standart ATR bazlı Supertrend.
+// trend true ise yükseliş, line aktif Supertrend çizgisidir.
+func computeSupertrend(high, low, close *ta.Series, period int, multiplier float64) (trend bool, line float64) {
+	// Wilder ATR'nin oturması için periyodun birkaç katı kadar geriye bak
+	lookback := period * 10
+	highs := seriesWindow(high, lookback)
+	lows := seriesWindow(low, lookback)
+	closes := seriesWindow(close, lookback)
+	n := len(closes)
+	if n <= period || len(highs) != n || len(lows) != n {
+		return true, math.NaN()
+	}
+
+	atr := 0.0
+	var upperBand, lowerBand float64
+	trend = true
+	for i := 1; i < n; i++ {
+		tr := math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+		if i <= period {
+			atr += tr / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			atr = (atr*float64(period-1) + tr) / float64(period)
+		}
+
+		hl2 := (highs[i] + lows[i]) / 2
+		basicUpper := hl2 + multiplier*atr
+		basicLower := hl2 - multiplier*atr
+		if i == period {
+			upperBand, lowerBand = basicUpper, basicLower
+			trend = closes[i] >= hl2
+			continue
+		}
+
+		// Final bantlar: önceki kapanış bandı kırmadıkça bant geri çekilmez
+		if basicUpper < upperBand || closes[i-1] > upperBand {
+			upperBand = basicUpper
+		}
+		if basicLower > lowerBand || closes[i-1] < lowerBand {
+			lowerBand = basicLower
+		}
+
+		if trend && closes[i] < lowerBand {
+			trend = false
+		} else if !trend && closes[i] > upperBand {
+			trend = true
+		}
+	}
+	if trend {
+		return true, lowerBand
+	}
+	return false, upperBand
+}
+
+// computeParabolicSAR - Wilder Parabolic SAR. Son bardaki SAR değerini, yönünü (true = yükseliş)
+// ve yönün bu barda dönüp dönmediğini döndürür. Hesap son psarLookback bar üzerinden yapılır.
+func computeParabolicSAR(high, low *ta.Series, step, maxAcc float64) (sar float64, uptrend, flipped bool) {
+	const psarLookback = 200
+	highs := seriesWindow(high, psarLookback)
+	lows := seriesWindow(low, psarLookback)
+	n := len(highs)
+	if n < 3 || len(lows) != n {
+		return math.NaN(), true, false
+	}
+
+	uptrend = true
+	sar, ep, af := lows[0], highs[0], step
+	for i := 1; i < n; i++ {
+		prevUp := uptrend
+		sar += af * (ep - sar)
+		if uptrend {
+			// SAR önceki iki barın dibinin üstüne çıkamaz
+			sar = math.Min(sar, math.Min(lows[i-1], lows[max(i-2, 0)]))
+			if lows[i] < sar {
+				uptrend = false
+				sar, ep, af = ep, lows[i], step
+			} else if highs[i] > ep {
+				ep, af = highs[i], math.Min(af+step, maxAcc)
+			}
+		} else {
+			sar = math.Max(sar, math.Max(highs[i-1], highs[max(i-2, 0)]))
+			if highs[i] > sar {
+				uptrend = true
+				sar, ep, af = ep, highs[i], step
+			} else if lows[i] < ep {
+				ep, af = lows[i], math.Min(af+step, maxAcc)
+			}
+		}
+		flipped = uptrend != prevUp
+	}
+	return sar, uptrend, flipped
+}
+
+// midpoint - offset bar öncesinden başlayan period barlık (en yüksek + en düşük) / 2
+func midpoint(high, low *ta.Series, period, offset int) float64 {
+	if high.Len() < period+offset || low.Len() < period+offset {
+		return math.NaN()
+	}
+	hh, ll := high.Last(offset), low.Last(offset)
+	for i := offset + 1; i < offset+period; i++ {
+		hh = math.Max(hh, high.Last(i))
+		ll = math.Min(ll, low.Last(i))
+	}
+	return (hh + ll) / 2
+}
+
+// computeIchimokuCloud - güncel bara denk gelen Senkou Span A ve B.
+// Spanlar kijunPeriod bar ileri kaydırıldığı için kijunPeriod bar önceki değerlerden hesaplanır.
+func computeIchimokuCloud(high, low *ta.Series, tenkanPeriod, kijunPeriod, senkouBPeriod int) (spanA, spanB float64) {
+	tenkan := midpoint(high, low, tenkanPeriod, kijunPeriod)
+	kijun := midpoint(high, low, kijunPeriod, kijunPeriod)
+	spanA = (tenkan + kijun) / 2
+	spanB = midpoint(high, low, senkouBPeriod, kijunPeriod)
+	return spanA, spanB
+}
+
+// donchianChannel - güncel bar hariç son period barın en yüksek ve en düşük değeri.
+// Güncel bar dahil edilirse fiyat kanalın dışına hiç çıkamaz.
+func donchianChannel(high, low *ta.Series, period int) (upper, lower float64) {
+	if period < 1 || high.Len() < period+1 || low.Len() < period+1 {
+		return math.NaN(), math.NaN()
+	}
+	upper, lower = high.Last(1), low.Last(1)
+	for i := 2; i <= period; i++ {
+		upper = math.Max(upper, high.Last(i))
+		lower = math.Min(lower, low.Last(i))
+	}
+	return upper, lower
+}
+
+// rangeRatio - son period barın (en yüksek - en düşük) / en düşük oranı.
+// Seride period'dan az bar varsa mevcut barlar kullanılır.
+func rangeRatio(high, low *ta.Series, period int) float64 {
+	period = min(period, high.Len(), low.Len())
+	if period < 1 {
+		return math.NaN()
+	}
+	hh, ll := high.Last(0), low.Last(0)
+	for i := 1; i < period; i++ {
+		hh = math.Max(hh, high.Last(i))
+		ll = math.Min(ll, low.Last(i))
+	}
+	if ll <= 0 {
+		return math.NaN()
+	}
+	return (hh - ll) / ll
+}
+
+// computeStochRSI - standart StochRSI: Wilder RSI'nin stochPeriod barlık stokastiği,
+// kPeriod SMA ile %K, %K'nın dPeriod SMA'sı ile %D. Değerler 0-100 aralığındadır.
+func computeStochRSI(close *ta.Series, rsiPeriod, stochPeriod, kPeriod, dPeriod int) (k, d float64) {
+	// RSI'nin oturması için ekstra geçmiş
+	need := rsiPeriod*10 + stochPeriod + kPeriod + dPeriod
+	closes := seriesWindow(close, need)
+	n := len(closes)
+	if rsiPeriod < 1 || stochPeriod < 1 || kPeriod < 1 || dPeriod < 1 ||
+		n <= rsiPeriod+stochPeriod+kPeriod+dPeriod {
+		return math.NaN(), math.NaN()
+	}
+
+	rsi := make([]float64, 0, n)
+	avgGain, avgLoss := 0.0, 0.0
+	for i := 1; i < n; i++ {
+		change := closes[i] - closes[i-1]
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+		if i <= rsiPeriod {
+			avgGain += gain / float64(rsiPeriod)
+			avgLoss += loss / float64(rsiPeriod)
+			if i < rsiPeriod {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(rsiPeriod-1) + gain) / float64(rsiPeriod)
+			avgLoss = (avgLoss*float64(rsiPeriod-1) + loss) / float64(rsiPeriod)
+		}
+		value := 100.0
+		if avgLoss > 0 {
+			value = 100 - 100/(1+avgGain/avgLoss)
+		}
+		rsi = append(rsi, value)
+	}
+
+	// RSI'nin stokastiği
+	stoch := make([]float64, 0, len(rsi))
+	for i := stochPeriod - 1; i < len(rsi); i++ {
+		hh, ll := rsi[i], rsi[i]
+		for j := i - stochPeriod + 1; j < i; j++ {
+			hh = math.Max(hh, rsi[j])
+			ll = math.Min(ll, rsi[j])
+		}
+		value := 50.0 // düz RSI'de nötr
+		if hh > ll {
+			value = (rsi[i] - ll) / (hh - ll) * 100
+		}
+		stoch = append(stoch, value)
+	}
+
+	kLine := smaSlice(stoch, kPeriod)
+	dLine := smaSlice(kLine, dPeriod)
+	if len(dLine) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	return kLine[len(kLine)-1], dLine[len(dLine)-1]
+}
+
+// computeStochastic - hızlı stokastik: %K kapanışın kPeriod barlık aralıktaki konumu (0-100),
+// %D ise %K'nın dPeriod SMA'sı. Veri yetersizse NaN döner.
+func computeStochastic(high, low, close *ta.Series, kPeriod, dPeriod int) (k, d float64) {
+	need := kPeriod + dPeriod - 1
+	highs, lows, closes := seriesWindow(high, need), seriesWindow(low, need), seriesWindow(close, need)
+	if kPeriod < 1 || dPeriod < 1 || len(closes) < need || len(highs) != len(closes) || len(lows) != len(closes) {
+		return math.NaN(), math.NaN()
+	}
+	kLine := make([]float64, 0, dPeriod)
+	for i := kPeriod - 1; i < need; i++ {
+		hh, ll := highs[i], lows[i]
+		for j := i - kPeriod + 1; j < i; j++ {
+			hh = math.Max(hh, highs[j])
+			ll = math.Min(ll, lows[j])
+		}
+		value := 50.0
+		if hh > ll {
+			value = (closes[i] - ll) / (hh - ll) * 100
+		}
+		kLine = append(kLine, value)
+	}
+	dLine := smaSlice(kLine, dPeriod)
+	return kLine[len(kLine)-1], dLine[len(dLine)-1]
+}
+
+// smaSlice - değerlerin period barlık hareketli ortalama dizisi
+func smaSlice(values []float64, period int) []float64 {
+	if period < 1 || len(values) < period {
+		return nil
+	}
+	// Kayan toplam yerine her pencere yeniden toplanır: çıkarma hatası 0/100 sınırlarını aşmasın
+	res := make([]float64, 0, len(values)-period+1)
+	for i := period; i <= len(values); i++ {
+		sum := 0.0
+		for _, value := range values[i-period : i] {
+			sum += value
+		}
+		res = append(res, sum/float64(period))
+	}
+	return res
+}
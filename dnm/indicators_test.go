@@ -0,0 +1,88 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStochRSIRange(t *testing.T) {
+	closes := wilderCloses(200)
+	valid := 0
+	for n := 1; n <= len(closes); n++ {
+		k, d := computeStochRSI(newSeries(closes[:n]...), 14, 14, 3, 3)
+		if math.IsNaN(k) || math.IsNaN(d) {
+			continue
+		}
+		valid++
+		if k < 0 || k > 100 || d < 0 || d > 100 {
+			t.Fatalf("bar %d: k=%.4f d=%.4f outside [0, 100]", n, k, d)
+		}
+	}
+	if valid < 150 {
+		t.Errorf("only %d bars produced values", valid)
+	}
+
+	// Sonda güçlü yükselişte RSI pencerenin zirvesinde kalır, %K 100'e oturur
+	rally := append(closes[:100:100], make([]float64, 10)...)
+	for i := 100; i < len(rally); i++ {
+		rally[i] = rally[i-1] + 1
+	}
+	k, d := computeStochRSI(newSeries(rally...), 14, 14, 3, 3)
+	assertFloat(t, "k after rally", k, 100)
+	if d > k {
+		t.Errorf("d=%.4f above k=%.4f after rally", d, k)
+	}
+}
+
+func TestComputeDominantPeriod(t *testing.T) {
+	sine := func(n int, period, trend float64) []float64 {
+		return priceSeries{amplitude: 3, period: period, trend: trend}.closes(n)
+	}
+	tests := []struct {
+		name     string
+		closes   []float64
+		lookback int
+		want     int
+	}{
+		{name: "period 16", closes: sine(256, 16, 0), lookback: 128, want: 16},
+		{name: "period 32", closes: sine(256, 32, 0), lookback: 128, want: 32},
+		{name: "period 10 with drift", closes: sine(300, 10, 0.01), lookback: 200, want: 10},
+		{name: "flat series", closes: make([]float64, 64), lookback: 64},
+		{name: "too few bars", closes: sine(6, 4, 0), lookback: 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeDominantPeriod(newSeries(tt.closes...), tt.lookback); got != tt.want {
+				t.Errorf("dominant period = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCumulativeDelta(t *testing.T) {
+	// Yükselen ve barın üst yarısında kapanan 10 bar: her bar delta = 100 * (2*c - h - l) / (h - l) = 50
+	var opens, highs, lows, closes, volumes []float64
+	for i := 0; i < 10; i++ {
+		base := 100 + float64(i)
+		opens = append(opens, base)
+		highs = append(highs, base+1.5)
+		lows = append(lows, base-0.5)
+		closes = append(closes, base+1)
+		volumes = append(volumes, 100)
+	}
+	delta := computeCumulativeDelta(newSeries(closes...), newSeries(opens...), newSeries(highs...),
+		newSeries(lows...), newSeries(volumes...), 5)
+	assertFloat(t, "delta", delta, 250)
+
+	buy, sell := deltaRestrictions(delta)
+	if buy != 0 {
+		t.Errorf("bullish delta blocked buys: %s", buy)
+	}
+	if sell != RestrictionDeltaFilter {
+		t.Errorf("bullish delta did not block sells: %s", sell)
+	}
+
+	// Aralığı sıfır olan barda yön açılış-kapanıştan alınır
+	flat := computeCumulativeDelta(newSeries(99), newSeries(100), newSeries(100), newSeries(100), newSeries(40), 5)
+	assertFloat(t, "zero-range bar", flat, -40)
+}
@@ -0,0 +1,72 @@
+package dnm
+
+import "math"
+
+// SessionKPI - bir session'ın (UTC gün) performans özeti
+type SessionKPI struct {
+	SessionTrades      int
+	SessionWinRate     float64
+	SessionPnL         float64
+	SessionMaxDrawdown float64 // session içi gerçekleşmiş PnL zirvesinden en büyük düşüş
+	AvgHoldBars        float64
+	GridUtilization    float64 // emri açık seviye / toplam seviye
+}
+
+// sessionTracker - session boyunca kapanan işlemleri biriktirir
+type sessionTracker struct {
+	trades   int
+	wins     int
+	pnl      float64
+	peak     float64
+	maxDD    float64
+	holdBars int
+	entries  map[string]int // emir tag'i -> giriş bar index'i
+}
+
+func newSessionTracker() *sessionTracker {
+	return &sessionTracker{entries: make(map[string]int)}
+}
+
+// recordEntry - seviye emri açıldığında çağrılır (tutma süresi için)
+func (t *sessionTracker) recordEntry(tag string, barIndex int) {
+	t.entries[tag] = barIndex
+}
+
+// recordClose - kapanan işlemi session toplamlarına ekler
+func (t *sessionTracker) recordClose(trade closedTrade, barIndex int) {
+	t.trades++
+	if trade.PnL > 0 {
+		t.wins++
+	}
+	t.pnl += trade.PnL
+	t.peak = math.Max(t.peak, t.pnl)
+	t.maxDD = math.Max(t.maxDD, t.peak-t.pnl)
+	if entry, ok := t.entries[trade.Order.Tag]; ok {
+		t.holdBars += barIndex - entry
+		delete(t.entries, trade.Order.Tag)
+	}
+}
+
+// computeSessionKPI - biriken değerlerden KPI özeti; levels grid kullanım oranı için
+func (t *sessionTracker) computeSessionKPI(levels map[string]GridLevel) SessionKPI {
+	kpi := SessionKPI{
+		SessionTrades:      t.trades,
+		SessionPnL:         t.pnl,
+		SessionMaxDrawdown: t.maxDD,
+	}
+	if t.trades > 0 {
+		kpi.SessionWinRate = float64(t.wins) / float64(t.trades)
+		kpi.AvgHoldBars = float64(t.holdBars) / float64(t.trades)
+	}
+	if len(levels) > 0 {
+		buys, sells := executedLevelCounts(levels)
+		kpi.GridUtilization = float64(buys+sells) / float64(len(levels))
+	}
+	return kpi
+}
+
+// reset - yeni session için sayaçları sıfırlar; açık emirlerin giriş barları korunur
+func (t *sessionTracker) reset() {
+	entries := t.entries
+	*t = sessionTracker{entries: entries}
+}
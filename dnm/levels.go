@@ -0,0 +1,740 @@
+package dnm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// Grid seviye tipleri
+const (
+	LevelBuy  = "buy"
+	LevelSell = "sell"
+)
+
+// maxGridLevels - GridPro'da bir taraftaki en fazla grid seviyesi
+const maxGridLevels = 8
+
+// gridWarmupNum - grid stratejilerinin warmup bar sayısı; grid bu kadar bar görmeden kurulmaz
+const gridWarmupNum = 100
+
+// gridWarmedUp - barCount bar görüldükten sonra grid kurulabilir mi?
+func gridWarmedUp(barCount int) bool {
+	return barCount >= gridWarmupNum
+}
+
+// GridLevel - tek bir grid seviyesinin durumu
+type GridLevel struct {
+	Name       string
+	Index      int // 1'den başlar, base fiyattan uzaklaştıkça artar
+	Type       string
+	Price      float64
+	Executed   bool
+	Active     bool
+	StopLoss   float64 // 0 ise ATR bazlı stop kullanılır
+	TakeProfit float64 // 0 ise tp_type hedefi kullanılır; ratchet ile dolmamış alışlarda yükseltilir
+	Wins       int
+	Losses     int
+
+	SizeMultiplier float64 // emir boyutu = basePositionSize * SizeMultiplier
+
+	PartialFillCount int     // sermaye yetersizliğinden kısmi açılan emir sayısı
+	PartialFillSize  float64 // son kısmi emirde açılamayan miktar, sonraki tam emre eklenir
+	EntryBarIndex    int     // seviyenin son emrinin açıldığı bar (max_hold_bars için)
+	FillCount        int     // seviye Executed olduğundan beri açılan emir sayısı (max_fills_per_level)
+	Pinned           bool    // birleştirme/bölme ile fiyatlandı; updateGridLevels base ± i*spacing'e geri çekmez
+}
+
+// LevelSizingFunc - seviye index'ine göre boyut çarpanı
+type LevelSizingFunc func(levelIndex int) float64
+
+// uniformSizing - tüm seviyeler aynı boyut
+func uniformSizing(int) float64 {
+	return 1.0
+}
+
+// dcaSizing - base'ten her uzaklaşan seviyede boyut multiplier katına çıkar (DCA/piramit)
+func dcaSizing(multiplier float64) LevelSizingFunc {
+	return func(levelIndex int) float64 {
+		return math.Pow(multiplier, float64(levelIndex-1))
+	}
+}
+
+// Piramit boyutlandırma modları (pyramid_mode)
+const (
+	PyramidFlat = 1
+	PyramidUp   = 2 // base'e yakın seviyeler büyük
+	PyramidDown = 3 // base'ten uzak seviyeler büyük
+)
+
+// pyramidSize - levelIndex'inci seviyenin (1 = base'e en yakın) boyutu.
+// PyramidUp'ta boyut derinlikle doğrusal azalır, PyramidDown'da artar. totalLevels'ı aşan
+// index'ler (bölünmüş seviyeler) en dış seviye boyutunu alır.
+func pyramidSize(baseSize float64, levelIndex, totalLevels int, mode int) float64 {
+	if totalLevels < 1 || levelIndex < 1 {
+		return baseSize
+	}
+	levelIndex = min(levelIndex, totalLevels)
+	switch mode {
+	case PyramidUp:
+		return baseSize * float64(totalLevels-levelIndex+1) / float64(totalLevels)
+	case PyramidDown:
+		return baseSize * float64(levelIndex) / float64(totalLevels)
+	}
+	return baseSize
+}
+
+// GridLevelMap - grid seviyelerini kilit altında tutar.
+// GridLevel değer olarak saklanır; okunan kopya değiştirilirse Set ile geri yazılmalıdır.
+type GridLevelMap struct {
+	mu      sync.Mutex
+	m       map[string]GridLevel
+	retired map[string]bool // birleştirilerek kaldırılan seviyeler; updateGridLevels yeniden oluşturmaz
+}
+
+func newGridLevelMap() *GridLevelMap {
+	return &GridLevelMap{m: make(map[string]GridLevel), retired: make(map[string]bool)}
+}
+
+// Get - seviyenin bir kopyasını döndürür
+func (g *GridLevelMap) Get(name string) (GridLevel, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	level, ok := g.m[name]
+	return level, ok
+}
+
+// Set - seviyeyi yazar (yoksa ekler)
+func (g *GridLevelMap) Set(name string, level GridLevel) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.m[name] = level
+}
+
+// Range - seviyeleri fiyata göre artan sırada dolaşır, fn false dönerse durur.
+// fn kilit dışında çağrılır, içinden Get/Set kullanılabilir.
+func (g *GridLevelMap) Range(fn func(name string, level GridLevel) bool) {
+	for _, level := range g.sorted() {
+		if !fn(level.Name, level) {
+			return
+		}
+	}
+}
+
+// Delete - seviyeyi siler
+func (g *GridLevelMap) Delete(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.m, name)
+}
+
+// Len - seviye sayısı
+func (g *GridLevelMap) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.m)
+}
+
+// Snapshot - map'in bağımsız bir kopyasını döndürür
+func (g *GridLevelMap) Snapshot() map[string]GridLevel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make(map[string]GridLevel, len(g.m))
+	for name, level := range g.m {
+		res[name] = level
+	}
+	return res
+}
+
+// Replace - tüm seviyeleri verilen map ile değiştirir
+func (g *GridLevelMap) Replace(levels map[string]GridLevel) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.m = make(map[string]GridLevel, len(levels))
+	for name, level := range levels {
+		g.m[name] = level
+	}
+}
+
+// Retire - seviyeyi siler ve ResetLayout'a kadar yeniden oluşturulmamak üzere işaretler
+func (g *GridLevelMap) Retire(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.m, name)
+	g.retired[name] = true
+}
+
+// ResetLayout - birleştirme/bölme kalıcılığını kaldırır: kaldırılan seviyeler bir sonraki güncellemede
+// yeniden oluşturulur, sabitlenmiş seviyeler yeniden base ± i*spacing'e fiyatlanır. Base taşındığında çağrılır.
+func (g *GridLevelMap) ResetLayout() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.retired = make(map[string]bool)
+	for name, level := range g.m {
+		level.Pinned = false
+		g.m[name] = level
+	}
+}
+
+func (g *GridLevelMap) isRetired(name string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.retired[name]
+}
+
+func (g *GridLevelMap) sorted() []GridLevel {
+	g.mu.Lock()
+	list := make([]GridLevel, 0, len(g.m))
+	for _, level := range g.m {
+		list = append(list, level)
+	}
+	g.mu.Unlock()
+	sortLevelsByPrice(list)
+	return list
+}
+
+func sortLevelsByPrice(list []GridLevel) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Price == list[j].Price {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Price < list[j].Price
+	})
+}
+
+// suggestGridCount - fiyat aralığını base'in iki yanında spacingPct aralıklarla kapsayan
+// seviye sayısı, [3, 20] aralığına sınırlanır
+func suggestGridCount(rangeRatio, spacingPct float64) int {
+	if math.IsNaN(rangeRatio) || spacingPct <= 0 {
+		return 0
+	}
+	count := int(rangeRatio / (2 * spacingPct / 100))
+	return min(max(count, 3), 20)
+}
+
+// levelName - "B1", "S3" gibi seviye adı üretir
+func levelName(levelType string, index int) string {
+	if levelType == LevelBuy {
+		return fmt.Sprintf("B%d", index)
+	}
+	return fmt.Sprintf("S%d", index)
+}
+
+// updateGridLevels - seviye fiyatlarını günceller, Executed durumunu korur.
+// Fiyatlar borsanın tick size'ına yuvarlanır. Base fiyattan maxDistancePct'den
+// uzak seviyeler oluşturulmaz (0 = sınırsız); atlanan seviye sayısını döndürür.
+// Base'e neutralZonePct'den yakın seviyeler de oluşturulmaz (sayılmaz).
+// sizing nil ise tüm seviyeler aynı boyuttadır.
+func updateGridLevels(levels *GridLevelMap, gridBasePrice, spacing float64, baseGridCount int, tickSize,
+	maxDistancePct, neutralZonePct float64, sizing LevelSizingFunc) int {
+	if sizing == nil {
+		sizing = uniformSizing
+	}
+	skipped := 0
+	for i := 1; i <= baseGridCount; i++ {
+		for _, levelType := range []string{LevelBuy, LevelSell} {
+			name := levelName(levelType, i)
+			price := gridBasePrice + spacing*float64(i)
+			if levelType == LevelBuy {
+				price = gridBasePrice - spacing*float64(i)
+			}
+			level, ok := levels.Get(name)
+			if levels.isRetired(name) || (ok && level.Pinned) {
+				continue // birleştirilmiş/bölünmüş düzen base taşınana kadar korunur
+			}
+			if maxDistancePct > 0 && gridBasePrice > 0 &&
+				math.Abs(price-gridBasePrice)/gridBasePrice*100 > maxDistancePct {
+				skipped++
+				if ok && !level.Executed {
+					levels.Delete(name)
+				}
+				continue
+			}
+			if isInNeutralZone(price, gridBasePrice, neutralZonePct) {
+				if ok && !level.Executed {
+					levels.Delete(name)
+				}
+				continue
+			}
+			if !ok {
+				level = GridLevel{Name: name, Index: i, Type: levelType, Active: true}
+			}
+			level.Price = snapToTick(price, tickSize)
+			level.SizeMultiplier = sizing(i)
+			levels.Set(name, level)
+		}
+	}
+	return skipped
+}
+
+// floatBandLevels - ATR Band modunda alış seviyelerini bu barın band fiyatlarına taşır
+func floatBandLevels(levels *GridLevelMap, bands []float64, tickSize float64) {
+	for i, band := range bands {
+		name := levelName(LevelBuy, i+1)
+		if level, ok := levels.Get(name); ok {
+			level.Price = snapToTick(band, tickSize)
+			levels.Set(name, level)
+		}
+	}
+}
+
+// CloneGridState - seviyelerin newSpacing ile yeniden fiyatlanmış kopyası. Emri açık seviyeler fiyat ve
+// istatistikleriyle aynen kalır, diğerleri aynı base etrafında index * newSpacing uzaklığa taşınır.
+// Çalışan grid'in aralığını pozisyon kapatmadan değiştirmek için kullanılır (EvenOdd hariç).
+func CloneGridState(levels map[string]GridLevel, gridBasePrice, newSpacing float64) map[string]GridLevel {
+	res := make(map[string]GridLevel, len(levels))
+	for name, level := range levels {
+		if !level.Executed {
+			offset := newSpacing * float64(level.Index)
+			if level.Type == LevelBuy {
+				offset = -offset
+			}
+			level.Price = gridBasePrice + offset
+		}
+		res[name] = level
+	}
+	return res
+}
+
+// createEvenOddLevels - base'in iki yanına count'ar seviye dizer. Seviyeler en alttan başlayarak
+// 1..2*count numaralanır; çift index'ler yalnızca alış, tek index'ler yalnızca satış seviyesidir.
+func createEvenOddLevels(basePrice float64, count int, spacing float64) map[string]GridLevel {
+	res := make(map[string]GridLevel, 2*count)
+	for index := 1; index <= 2*count; index++ {
+		// 1..count base'in altında (uzaktan yakına), count+1..2*count üstünde
+		offset := index - count - 1
+		if index > count {
+			offset = index - count
+		}
+		levelType := LevelSell
+		if index%2 == 0 {
+			levelType = LevelBuy
+		}
+		name := levelName(levelType, index)
+		res[name] = GridLevel{
+			Name:   name,
+			Index:  index,
+			Type:   levelType,
+			Price:  basePrice + spacing*float64(offset),
+			Active: true,
+
+			SizeMultiplier: 1.0,
+		}
+	}
+	return res
+}
+
+// updateEvenOddLevels - EvenOdd seviye fiyatlarını günceller, Executed durumunu korur
+func updateEvenOddLevels(levels *GridLevelMap, gridBasePrice, spacing float64, count int, tickSize float64) {
+	for name, fresh := range createEvenOddLevels(gridBasePrice, count, spacing) {
+		level, ok := levels.Get(name)
+		if !ok {
+			level = fresh
+		}
+		level.Price = snapToTick(fresh.Price, tickSize)
+		levels.Set(name, level)
+	}
+}
+
+// clearPendingLevels - emri olmayan seviyeleri siler (seviye düzeni değiştiğinde)
+func clearPendingLevels(levels *GridLevelMap) {
+	levels.Range(func(name string, level GridLevel) bool {
+		if !level.Executed {
+			levels.Delete(name)
+		}
+		return true
+	})
+}
+
+// mergeNearbyLevels - fiyata göre sıralı seviyelerde aralarındaki mesafe minSpacing'den az olan
+// aynı tipteki çiftleri birleştirir: index'i küçük olan kalır, fiyatı ikisinin ortasına çekilir ve
+// updateGridLevels'ın geri almaması için sabitlenir. Emri olan seviyeler birleştirilmez.
+func mergeNearbyLevels(levels map[string]GridLevel, minSpacing float64) map[string]GridLevel {
+	list := make([]GridLevel, 0, len(levels))
+	for _, level := range levels {
+		list = append(list, level)
+	}
+	sortLevelsByPrice(list)
+	res := make(map[string]GridLevel, len(levels))
+	var prev *GridLevel
+	for _, cur := range list {
+		if prev != nil && prev.Type == cur.Type && cur.Price-prev.Price < minSpacing && !prev.Executed && !cur.Executed {
+			keep := *prev
+			if cur.Index < keep.Index {
+				keep = cur
+			}
+			keep.Price = (prev.Price + cur.Price) / 2
+			keep.Pinned = true
+			delete(res, prev.Name)
+			res[keep.Name] = keep
+			prev = &keep
+			continue
+		}
+		res[cur.Name] = cur
+		level := cur
+		prev = &level
+	}
+	return res
+}
+
+// splitNearestLevel - fiyata en yakın, emri olmayan aktif seviyeyi fiyatının ±spacing/2'sindeki iki seviyeye böler.
+// Orijinal seviye üst yarıya taşınır, alt yarı aynı tipte bir sonraki boş index ile eklenir; ikisi de
+// sabitlenir, böylece updateGridLevels bölmeyi bir sonraki barda geri almaz.
+// Seviyenin tipinde maxPerSide seviye varsa bölme yapılmaz; bölündüyse true döner.
+func splitNearestLevel(levels map[string]GridLevel, currentPrice, spacing float64, maxPerSide int) (map[string]GridLevel, bool) {
+	var nearest GridLevel
+	found := false
+	for _, level := range levels {
+		if level.Executed || !level.Active {
+			continue
+		}
+		if !found || math.Abs(level.Price-currentPrice) < math.Abs(nearest.Price-currentPrice) {
+			nearest, found = level, true
+		}
+	}
+	if !found || spacing <= 0 {
+		return levels, false
+	}
+	count, maxIndex := 0, 0
+	for _, level := range levels {
+		if level.Type == nearest.Type {
+			count++
+			maxIndex = max(maxIndex, level.Index)
+		}
+	}
+	if count >= maxPerSide {
+		return levels, false
+	}
+	res := make(map[string]GridLevel, len(levels)+1)
+	for name, level := range levels {
+		res[name] = level
+	}
+	lower := nearest
+	lower.Index = maxIndex + 1
+	lower.Name = levelName(nearest.Type, lower.Index)
+	lower.Price = nearest.Price - spacing/2
+	lower.Wins, lower.Losses = 0, 0
+	lower.Pinned = true
+	nearest.Price += spacing / 2
+	nearest.Pinned = true
+	res[nearest.Name] = nearest
+	res[lower.Name] = lower
+	return res, true
+}
+
+// maxLevelIndex - seviyeler arasındaki en büyük index (bölünmüş seviyeler grid sayısını aşabilir)
+func maxLevelIndex(levels map[string]GridLevel) int {
+	res := 0
+	for _, level := range levels {
+		res = max(res, level.Index)
+	}
+	return res
+}
+
+// maxRatchetMultiplier - ratchet'li TP mesafesi base TP'nin en fazla bu katı olabilir
+const maxRatchetMultiplier = 3.0
+
+// ratchetMultiplier - dolan her seviye için base TP mesafesine ratchetPct eklenir, 3 katla sınırlıdır
+func ratchetMultiplier(filledCount int, ratchetPct float64) float64 {
+	if filledCount <= 0 || ratchetPct <= 0 {
+		return 1.0
+	}
+	return math.Min(1+ratchetPct*float64(filledCount), maxRatchetMultiplier)
+}
+
+// ratchetTakeProfits - dolmamış seviyelerin TP'sini alışta level fiyatı + ATR * tpATRMult * çarpan, satışta
+// level fiyatı - ATR * tpATRMult * çarpan yapar. Çarpan her yön için o yönde dolan seviye sayısından hesaplanır.
+// Dolmuş seviyeler dolduklarındaki TP'yi korur; böylece trend sürdükçe yalnızca sonraki seviyeler daha uzağı hedefler.
+func ratchetTakeProfits(levels map[string]GridLevel, filledBuys, filledSells int, ratchetPct, currentATR, tpATRMult float64) map[string]GridLevel {
+	buyMult := ratchetMultiplier(filledBuys, ratchetPct)
+	sellMult := ratchetMultiplier(filledSells, ratchetPct)
+	res := make(map[string]GridLevel, len(levels))
+	for name, level := range levels {
+		if !level.Executed {
+			level.TakeProfit = 0
+			if level.Type == LevelBuy && buyMult > 1 {
+				level.TakeProfit = level.Price + currentATR*tpATRMult*buyMult
+			} else if level.Type == LevelSell && sellMult > 1 {
+				level.TakeProfit = level.Price - currentATR*tpATRMult*sellMult
+			}
+		}
+		res[name] = level
+	}
+	return res
+}
+
+// gridCycleComplete - tüm seviyeler emir açmış ya da pasifleşmişse grid döngüsü tamamlanmıştır
+func gridCycleComplete(levels map[string]GridLevel) bool {
+	if len(levels) == 0 {
+		return false
+	}
+	for _, level := range levels {
+		if level.Active && !level.Executed {
+			return false
+		}
+	}
+	return true
+}
+
+// executedLevelCounts - emri açık alış ve satış seviyesi sayıları
+func executedLevelCounts(levels map[string]GridLevel) (buys, sells int) {
+	for _, level := range levels {
+		if !level.Executed {
+			continue
+		}
+		if level.Type == LevelBuy {
+			buys++
+		} else {
+			sells++
+		}
+	}
+	return buys, sells
+}
+
+// adjustBaseForSentiment - korkuda (fgValue < fgLow) düşüş sürer beklentisiyle alışlar aşağıda kalsın diye
+// base'i yarım aralık aşağı, açgözlülükte (fgValue > fgHigh) satışlar geri çekilme öncesi yukarıda kalsın
+// diye yarım aralık yukarı kaydırır.
+func adjustBaseForSentiment(base, spacing, fgValue, fgLow, fgHigh float64) float64 {
+	switch {
+	case fgValue < fgLow:
+		return base - 0.5*spacing
+	case fgValue > fgHigh:
+		return base + 0.5*spacing
+	}
+	return base
+}
+
+// LevelCluster - birbirine clusterRadius'tan yakın seviyeler (aynı barda birlikte tetiklenebilir)
+type LevelCluster struct {
+	CenterPrice float64  `json:"center_price"` // boyut ağırlıklı ortalama fiyat
+	LevelNames  []string `json:"level_names"`
+	TotalSize   float64  `json:"total_size"` // SizeMultiplier toplamı (basePositionSize cinsinden)
+}
+
+// clusterGridLevels - fiyata göre sıralı seviyeleri açgözlü tarar: kümenin ilk seviyesine
+// clusterRadius'tan yakın seviyeler aynı kümeye girer. Yalnızca birden çok seviyeli kümeler döner.
+func clusterGridLevels(levels map[string]GridLevel, clusterRadius float64) []LevelCluster {
+	list := make([]GridLevel, 0, len(levels))
+	for _, level := range levels {
+		list = append(list, level)
+	}
+	sortLevelsByPrice(list)
+	var res []LevelCluster
+	for start := 0; start < len(list); {
+		end := start + 1
+		for end < len(list) && list[end].Price-list[start].Price <= clusterRadius {
+			end++
+		}
+		if end-start > 1 {
+			var cluster LevelCluster
+			weighted := 0.0
+			for _, level := range list[start:end] {
+				size := level.SizeMultiplier
+				if size <= 0 {
+					size = 1
+				}
+				cluster.LevelNames = append(cluster.LevelNames, level.Name)
+				cluster.TotalSize += size
+				weighted += level.Price * size
+			}
+			cluster.CenterPrice = weighted / cluster.TotalSize
+			res = append(res, cluster)
+		}
+		start = end
+	}
+	return res
+}
+
+// isInNeutralZone - seviye base fiyata neutralZonePct'den yakın mı? (0 = kapalı)
+// Merkezdeki bu bölgede aynı barda karşıt alış ve satış emirleri tetiklenmesin diye emir konmaz.
+func isInNeutralZone(levelPrice, basePrice, neutralZonePct float64) bool {
+	if neutralZonePct <= 0 || basePrice <= 0 {
+		return false
+	}
+	return math.Abs(levelPrice-basePrice)/basePrice*100 < neutralZonePct
+}
+
+// trimGridLevels - index'i count'tan büyük seviyeleri siler
+func trimGridLevels(levels *GridLevelMap, count int) {
+	levels.Range(func(name string, level GridLevel) bool {
+		if level.Index > count {
+			levels.Delete(name)
+		}
+		return true
+	})
+}
+
+// snapToTick - fiyatı en yakın tick katına yuvarlar
+func snapToTick(price, tickSize float64) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	return math.Round(price/tickSize) * tickSize
+}
+
+// validateLevelSpacing - ardışık seviyeler arasında en az minGap (2 tick + yarım spread) olmasını kontrol eder.
+// İhlal varsa her iki tarafta da koşulu sağlayan en büyük seviye sayısını,
+// ihlal yoksa 0 döndürür.
+func validateLevelSpacing(levels map[string]GridLevel, minGap float64) int {
+	valid := 0
+	for _, levelType := range []string{LevelBuy, LevelSell} {
+		prev, ok := levels[levelName(levelType, 1)]
+		if !ok {
+			continue
+		}
+		for i := 2; ; i++ {
+			cur, ok := levels[levelName(levelType, i)]
+			if !ok {
+				break
+			}
+			if math.Abs(cur.Price-prev.Price) < minGap {
+				if valid == 0 || i-1 < valid {
+					valid = i - 1
+				}
+				break
+			}
+			prev = cur
+		}
+	}
+	return valid
+}
+
+// isProfitable - bir seviye aralığı, giriş+çıkış maliyetini (costPct = komisyon + spread, her iki yönde) karşılıyor mu?
+func isProfitable(spacing, price, costPct float64) bool {
+	if price <= 0 {
+		return false
+	}
+	return spacing/price*100 > 2*costPct
+}
+
+// isLevelTriggered - bar aralığı seviyenin ±currentATR*zone bölgesine değdi mi?
+func isLevelTriggered(levelPrice, low, high, currentATR float64, zone float64) bool {
+	band := currentATR * zone
+	return low <= levelPrice+band && high >= levelPrice-band
+}
+
+// levelReady - seviye emir açabilir mi? Executed seviye, maxFills dolmadıysa ve son emrinden bu yana
+// cooldownBars geçtiyse tekrar açabilir (DCA).
+func levelReady(level GridLevel, barIndex, maxFills, cooldownBars int) bool {
+	return !level.Executed || (level.FillCount < maxFills && barIndex-level.EntryBarIndex >= cooldownBars)
+}
+
+// recordLevelFill - seviyede açılan emri işler; seviye yeni Executed oluyorsa dolum sayacı sıfırdan başlar
+func recordLevelFill(level *GridLevel, barIndex int) {
+	if !level.Executed {
+		level.FillCount = 0
+	}
+	level.FillCount++
+	level.Executed = true
+	level.StopLoss = 0
+	level.EntryBarIndex = barIndex
+}
+
+// closeConfirmed - kapanış seviyenin ötesinde mi? (alışta altında, satışta üstünde; fitil dokunuşu sayılmaz)
+func closeConfirmed(levelType string, levelPrice, close float64) bool {
+	if levelType == LevelBuy {
+		return close <= levelPrice
+	}
+	return close >= levelPrice
+}
+
+// updateWinRate - seviyenin kapanan işleminin sonucunu kaydeder
+func updateWinRate(level *GridLevel, won bool) {
+	if won {
+		level.Wins++
+	} else {
+		level.Losses++
+	}
+}
+
+// shouldDeactivate - her 5 işlemde bir, kazanma oranı minWR altındaysa true
+func shouldDeactivate(level GridLevel, minWR float64) bool {
+	fills := level.Wins + level.Losses
+	if fills == 0 || fills%5 != 0 {
+		return false
+	}
+	return float64(level.Wins)/float64(fills) < minWR
+}
+
+// recordLevelOutcome - sonucu seviyeye yazar, sürekli kaybeden seviyeyi kalıcı olarak kapatır.
+// stillOpen, seviyenin başka dolumlarının açık olduğunu belirtir (max_fills_per_level > 1);
+// bu durumda Executed, releaseClosedLevels'ta olduğu gibi son dolum kapanana kadar korunur.
+func recordLevelOutcome(s *strat.StratJob, levels *GridLevelMap, stats *GridStats, name string, won bool, minWR float64, stillOpen bool) {
+	level, ok := levels.Get(name)
+	if !ok {
+		return
+	}
+	updateWinRate(&level, won)
+	if level.Active && shouldDeactivate(level, minWR) {
+		level.Active = false
+		if !stillOpen {
+			level.Executed = false // son pozisyon kapandı, pasif seviye emir tutmaz
+		}
+		stats.DeactivatedLevels++
+		s.Infof("Grid level %s deactivated: win rate %d/%d below %.2f",
+			name, level.Wins, level.Wins+level.Losses, minWR)
+	}
+	levels.Set(name, level)
+}
+
+// validateGridState - emir açmadan önce seviye map'inin tutarlılığını kontrol eder.
+// sided true ise alışların base altında, satışların üstünde olması beklenir (EvenOdd'da false).
+// Bulunan her sorun için bir açıklama döndürür; boş slice durumun geçerli olduğunu gösterir.
+// Pasifleştirilmiş ama açık dolumu kalan seviye (Executed, !Active) geçerlidir.
+func validateGridState(base float64, levels map[string]GridLevel, sided bool) []string {
+	var issues []string
+	list := make([]GridLevel, 0, len(levels))
+	for _, level := range levels {
+		list = append(list, level)
+	}
+	sortLevelsByPrice(list)
+	for i, level := range list {
+		if level.Price <= 0 || math.IsNaN(level.Price) {
+			issues = append(issues, fmt.Sprintf("level %s has invalid price %.8f", level.Name, level.Price))
+		}
+		if sided && level.Type == LevelBuy && level.Price >= base {
+			issues = append(issues, fmt.Sprintf("buy level %s at %.8f is not below base %.8f", level.Name, level.Price, base))
+		}
+		if sided && level.Type == LevelSell && level.Price <= base {
+			issues = append(issues, fmt.Sprintf("sell level %s at %.8f is not above base %.8f", level.Name, level.Price, base))
+		}
+		if i > 0 && list[i-1].Price == level.Price {
+			issues = append(issues, fmt.Sprintf("levels %s and %s share price %.8f", list[i-1].Name, level.Name, level.Price))
+		}
+	}
+	return issues
+}
+
+// gridTagPrefix - instance'a ait emir tag'lerinin öneki ("G0_").
+// Aynı sembolde çalışan farklı instance'ların (A/B test) emirleri bu önekle ayrılır.
+func gridTagPrefix(instanceID string) string {
+	return "G" + instanceID + "_"
+}
+
+// levelTag - seviye için emir tag'i ("G0_Buy_B1", "G0_Sell_S2")
+func levelTag(instanceID, levelType string, index int) string {
+	side := "Sell"
+	if levelType == LevelBuy {
+		side = "Buy"
+	}
+	return gridTagPrefix(instanceID) + side + "_" + levelName(levelType, index)
+}
+
+// levelNameFromTag - emir tag'inden seviye adını bulur; başka instance'ların tag'leri eşleşmez
+func levelNameFromTag(tag, instanceID string) (string, bool) {
+	rest, ok := strings.CutPrefix(tag, gridTagPrefix(instanceID))
+	if !ok {
+		return "", false
+	}
+	if name, ok := strings.CutPrefix(rest, "Buy_"); ok {
+		return name, true
+	}
+	if name, ok := strings.CutPrefix(rest, "Sell_"); ok {
+		return name, true
+	}
+	return "", false
+}
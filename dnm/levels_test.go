@@ -0,0 +1,314 @@
+package dnm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// GridLevelMap'e eşzamanlı okuma/yazma; -race ile çalıştırıldığında veri yarışı bulunmamalı
+func TestGridLevelMapConcurrentAccess(t *testing.T) {
+	levels := newGridLevelMap()
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				name := fmt.Sprintf("B%d", i%maxGridLevels+1)
+				level, _ := levels.Get(name)
+				level.Name = name
+				level.Price = float64(100 - i%maxGridLevels)
+				level.Executed = !level.Executed
+				levels.Set(name, level)
+				levels.Range(func(string, GridLevel) bool { return true })
+				if i%50 == w {
+					levels.Replace(levels.Snapshot())
+				}
+				_ = levels.Len()
+			}
+		}(w)
+	}
+	wg.Wait()
+	if got := levels.Len(); got != maxGridLevels {
+		t.Errorf("Len = %d, want %d", got, maxGridLevels)
+	}
+}
+
+func TestGridLevelMapCopySemantics(t *testing.T) {
+	levels := newGridLevelMap()
+	levels.Set("B1", GridLevel{Name: "B1", Price: 99})
+	level, _ := levels.Get("B1")
+	level.Executed = true
+	if stored, _ := levels.Get("B1"); stored.Executed {
+		t.Fatal("Get returned a reference; mutation leaked without Set")
+	}
+	levels.Set("B1", level)
+	if stored, _ := levels.Get("B1"); !stored.Executed {
+		t.Error("Set did not write the level back")
+	}
+	snap := levels.Snapshot()
+	delete(snap, "B1")
+	if _, ok := levels.Get("B1"); !ok {
+		t.Error("Snapshot shares storage with the map")
+	}
+}
+
+func TestWinRateDeactivation(t *testing.T) {
+	tests := []struct {
+		name          string
+		outcomes      string // W kazanç, L kayıp
+		deactivatedAt int    // seviyenin kapandığı işlem (1'den), 0 ise hiç
+	}{
+		{name: "3 of 5 wins stays active", outcomes: "WWWLLWLWLW"},
+		{name: "2 of 10 wins deactivates at tenth fill", outcomes: "WWLLLLLLLL", deactivatedAt: 10},
+		{name: "1 of 5 wins deactivates at fifth fill", outcomes: "WLLLL", deactivatedAt: 5},
+		{name: "checked only every 5 fills", outcomes: "LLLL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := GridLevel{Name: "B1", Active: true}
+			got := 0
+			for i, c := range tt.outcomes {
+				updateWinRate(&level, c == 'W')
+				if got == 0 && shouldDeactivate(level, 0.4) {
+					got = i + 1
+				}
+			}
+			if got != tt.deactivatedAt {
+				t.Errorf("deactivated at fill %d, want %d", got, tt.deactivatedAt)
+			}
+		})
+	}
+}
+
+func TestMaxLevelDistance(t *testing.T) {
+	levels := newGridLevelMap()
+	skipped := updateGridLevels(levels, 100, 3, 5, 0, 8, 0, nil)
+	if skipped != 6 {
+		t.Errorf("skipped = %d, want 6 (depths 9, 12, 15 on both sides)", skipped)
+	}
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{"B1": 97, "B2": 94, "S1": 103, "S2": 106})
+}
+
+func TestEvenOddLevelPattern(t *testing.T) {
+	const count = 8
+	levels := createEvenOddLevels(100, count, 1)
+	if len(levels) != 2*count {
+		t.Fatalf("got %d levels, want %d", len(levels), 2*count)
+	}
+	byIndex := make(map[int]GridLevel, len(levels))
+	for _, level := range levels {
+		byIndex[level.Index] = level
+	}
+	buysBelow, buysAbove, sellsBelow, sellsAbove := 0, 0, 0, 0
+	for index := 1; index <= 2*count; index++ {
+		level, ok := byIndex[index]
+		if !ok {
+			t.Fatalf("index %d missing", index)
+		}
+		wantType := LevelSell
+		if index%2 == 0 {
+			wantType = LevelBuy
+		}
+		if level.Type != wantType || level.Name != levelName(wantType, index) {
+			t.Errorf("index %d is %s %s, want %s", index, level.Type, level.Name, wantType)
+		}
+		if index > 1 && level.Price <= byIndex[index-1].Price {
+			t.Errorf("index %d price %.2f not above index %d", index, level.Price, index-1)
+		}
+		switch {
+		case level.Type == LevelBuy && level.Price < 100:
+			buysBelow++
+		case level.Type == LevelBuy:
+			buysAbove++
+		case level.Price < 100:
+			sellsBelow++
+		default:
+			sellsAbove++
+		}
+	}
+	if buysBelow == 0 || buysAbove == 0 || sellsBelow == 0 || sellsAbove == 0 {
+		t.Errorf("both types must sit on both sides: buys %d/%d sells %d/%d below/above",
+			buysBelow, buysAbove, sellsBelow, sellsAbove)
+	}
+	assertFloat(t, "lowest", byIndex[1].Price, 92)
+	assertFloat(t, "highest", byIndex[2*count].Price, 108)
+}
+
+func TestMergeFiveLevels(t *testing.T) {
+	levels := map[string]GridLevel{
+		"B1": {Name: "B1", Index: 1, Type: LevelBuy, Price: 99},
+		"B2": {Name: "B2", Index: 2, Type: LevelBuy, Price: 98},
+		"B3": {Name: "B3", Index: 3, Type: LevelBuy, Price: 97.8},
+		"B4": {Name: "B4", Index: 4, Type: LevelBuy, Price: 96},
+		"B5": {Name: "B5", Index: 5, Type: LevelBuy, Price: 95},
+	}
+	merged := mergeNearbyLevels(levels, 0.5)
+	assertLevelPrices(t, merged, map[string]float64{"B1": 99, "B2": 97.9, "B4": 96, "B5": 95})
+	if removed := len(levels) - len(merged); removed != 1 {
+		t.Errorf("merged %d pairs, want 1", removed)
+	}
+}
+
+func TestNeutralZone(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		want  bool
+	}{
+		{name: "just below base", price: 99.6, want: true},
+		{name: "just above base", price: 100.4, want: true},
+		{name: "one spacing away", price: 99},
+		{name: "zone edge excluded", price: 100.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isInNeutralZone(tt.price, 100, 0.5); got != tt.want {
+				t.Errorf("isInNeutralZone(%.2f) = %v, want %v", tt.price, got, tt.want)
+			}
+		})
+	}
+
+	// 0.5% bölge 1% aralıklı grid'de ilk seviyelere ulaşmaz; aralık 0.4%'e inerse ilk seviyeler atlanır
+	levels := newGridLevelMap()
+	updateGridLevels(levels, 100, 1, 2, 0, 0, 0.5, nil)
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{"B1": 99, "B2": 98, "S1": 101, "S2": 102})
+	levels = newGridLevelMap()
+	updateGridLevels(levels, 100, 0.4, 2, 0, 0, 0.5, nil)
+	assertLevelPrices(t, levels.Snapshot(), map[string]float64{"B2": 99.2, "S2": 100.8})
+}
+
+// Base fiyat 100'e tohumlanmış olsa bile grid warmup tamamlanmadan kurulmamalı
+func TestGridNotInitializedBeforeWarmup(t *testing.T) {
+	closes := newSeries()
+	gridBasePrice, initBar := 100.0, -1
+	for bar := 0; bar < gridWarmupNum+10; bar++ {
+		closes.Data = append(closes.Data, 100+float64(bar%5))
+		if initBar < 0 && gridWarmedUp(closes.Len()) {
+			gridBasePrice, initBar = closes.Last(0), bar
+		}
+		if bar < 10 && initBar >= 0 {
+			t.Fatalf("grid initialized at bar %d, before warmup", bar)
+		}
+	}
+	if initBar != gridWarmupNum-1 {
+		t.Errorf("grid initialized at bar %d, want %d", initBar, gridWarmupNum-1)
+	}
+	assertFloat(t, "base", gridBasePrice, 104)
+}
+
+func TestCloseConfirmFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		levelType        string
+		low, high, close float64
+		want             bool
+	}{
+		{name: "buy wick only", levelType: LevelBuy, low: 98.5, high: 100, close: 99.5},
+		{name: "buy closed below", levelType: LevelBuy, low: 98.5, high: 100, close: 98.8, want: true},
+		{name: "sell wick only", levelType: LevelSell, low: 98, high: 99.5, close: 98.5},
+		{name: "sell closed above", levelType: LevelSell, low: 98, high: 99.5, close: 99.2, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Seviye 99: fitil her durumda değer, kapanış onayı yalnızca gerçek geçişte
+			if !isLevelTriggered(99, tt.low, tt.high, 0, 0) {
+				t.Fatal("bar must touch the level")
+			}
+			if got := closeConfirmed(tt.levelType, 99, tt.close); got != tt.want {
+				t.Errorf("closeConfirmed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPyramidSize(t *testing.T) {
+	const total = 5
+	tests := []struct {
+		name string
+		mode int
+		want []float64 // index 1..total
+	}{
+		{name: "flat", mode: PyramidFlat, want: []float64{10, 10, 10, 10, 10}},
+		{name: "pyramid up", mode: PyramidUp, want: []float64{10, 8, 6, 4, 2}},
+		{name: "pyramid down", mode: PyramidDown, want: []float64{2, 4, 6, 8, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				assertFloat(t, levelName(LevelBuy, i+1), pyramidSize(10, i+1, total, tt.mode), want)
+			}
+		})
+	}
+	// Bölünmüş seviyeler (index > total) en dış seviyenin boyutunu alır
+	assertFloat(t, "split level", pyramidSize(10, total+2, total, PyramidUp), 2)
+}
+
+// max_fills_per_level=3 ve 5 bar cooldown: seviye 3 kez, aralarında en az 5 bar olacak şekilde açılır
+func TestMaxFillsPerLevel(t *testing.T) {
+	level := GridLevel{Name: "B1", Type: LevelBuy, Price: 99, Active: true}
+	var fills []int
+	totalFills := 0
+	for bar := 0; bar < 40; bar++ {
+		if !levelReady(level, bar, 3, 5) {
+			continue
+		}
+		recordLevelFill(&level, bar)
+		totalFills++
+		fills = append(fills, bar)
+	}
+	want := []int{0, 5, 10}
+	if len(fills) != len(want) {
+		t.Fatalf("level filled at bars %v, want %v", fills, want)
+	}
+	for i := range want {
+		if fills[i] != want[i] {
+			t.Errorf("fill %d at bar %d, want %d", i+1, fills[i], want[i])
+		}
+	}
+	if level.FillCount != 3 || totalFills != 3 {
+		t.Errorf("FillCount=%d totalFills=%d, want 3", level.FillCount, totalFills)
+	}
+
+	// Pozisyonlar kapanıp seviye serbest kalınca sayaç yeni döngüde sıfırdan başlar
+	level.Executed = false
+	recordLevelFill(&level, 50)
+	if level.FillCount != 1 {
+		t.Errorf("FillCount after release = %d, want 1", level.FillCount)
+	}
+}
+
+// max_fills_per_level > 1: kapanan dolum seviyeyi pasifleştirse de diğer dolum açıksa Executed korunur
+func TestDeactivationKeepsOpenFills(t *testing.T) {
+	tests := []struct {
+		name         string
+		stillOpen    bool
+		wantExecuted bool
+	}{
+		{name: "other fill still open", stillOpen: true, wantExecuted: true},
+		{name: "last fill closed", stillOpen: false, wantExecuted: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := newGridLevelMap()
+			levels.Set("B1", GridLevel{Name: "B1", Type: LevelBuy, Price: 99, Active: true, Executed: true,
+				FillCount: 2, Losses: 4})
+			stats := &GridStats{}
+			recordLevelOutcome(&strat.StratJob{}, levels, stats, "B1", false, 0.4, tt.stillOpen)
+
+			level, _ := levels.Get("B1")
+			if level.Active || stats.DeactivatedLevels != 1 {
+				t.Fatalf("level not deactivated: %+v, deactivated=%d", level, stats.DeactivatedLevels)
+			}
+			if level.Executed != tt.wantExecuted {
+				t.Errorf("Executed = %v, want %v", level.Executed, tt.wantExecuted)
+			}
+			if issues := validateGridState(100, levels.Snapshot(), true); len(issues) != 0 {
+				t.Errorf("deactivated level reported invalid: %v", issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package dnm
+
+import "sync"
+
+// mirrorPlan - birincil grid'in eş sembol için yayınladığı ters yönlü grid planı.
+// banbot'ta bir job yalnızca kendi sembolünde emir açabildiğinden, ters grid'i eş sembolün
+// kendi GridPro job'u bu plandan kurar. İki job max_concurrent_trades limitini plan üzerinden paylaşır.
+type mirrorPlan struct {
+	mu     sync.Mutex
+	source string
+	levels map[string]GridLevel // fiyatlar base'e oran olarak (price / base)
+	open   map[string]int       // sembol -> açık grid emri sayısı
+}
+
+// mirrorPlans - eş sembol -> *mirrorPlan
+var mirrorPlans sync.Map
+
+// mirrorLevels - seviye tiplerini ters çevirir (alış -> satış, satış -> alış).
+// Index ve fiyat korunur; emir ve kazanç geçmişi taşınmaz.
+func mirrorLevels(levels map[string]GridLevel) map[string]GridLevel {
+	res := make(map[string]GridLevel, len(levels))
+	for _, level := range levels {
+		mirrored := GridLevel{
+			Index:  level.Index,
+			Type:   LevelBuy,
+			Price:  level.Price,
+			Active: level.Active,
+
+			SizeMultiplier: level.SizeMultiplier,
+		}
+		if level.Type == LevelBuy {
+			mirrored.Type = LevelSell
+		}
+		mirrored.Name = levelName(mirrored.Type, mirrored.Index)
+		res[mirrored.Name] = mirrored
+	}
+	return res
+}
+
+// publishMirrorPlan - source sembolün seviyelerini ters çevirip target sembol için yayınlar
+func publishMirrorPlan(source, target string, levels map[string]GridLevel, basePrice float64) *mirrorPlan {
+	val, _ := mirrorPlans.LoadOrStore(target, &mirrorPlan{open: make(map[string]int)})
+	plan := val.(*mirrorPlan)
+	if basePrice <= 0 {
+		return plan
+	}
+	mirrored := mirrorLevels(levels)
+	for name, level := range mirrored {
+		level.Price /= basePrice
+		mirrored[name] = level
+	}
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+	plan.source = source
+	plan.levels = mirrored
+	return plan
+}
+
+// loadMirrorPlan - symbol için yayınlanmış ters grid planı
+func loadMirrorPlan(symbol string) (*mirrorPlan, bool) {
+	val, ok := mirrorPlans.Load(symbol)
+	if !ok {
+		return nil, false
+	}
+	plan := val.(*mirrorPlan)
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+	return plan, plan.levels != nil
+}
+
+// levelsAt - plan seviyelerini verilen base fiyata ölçekler
+func (p *mirrorPlan) levelsAt(basePrice float64) map[string]GridLevel {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	res := make(map[string]GridLevel, len(p.levels))
+	for name, level := range p.levels {
+		level.Price *= basePrice
+		res[name] = level
+	}
+	return res
+}
+
+// sharedOpen - symbol'ün açık emir sayısını kaydeder, diğer sembollerin toplamını döndürür
+func (p *mirrorPlan) sharedOpen(symbol string, open int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.open[symbol] = open
+	others := 0
+	for sym, n := range p.open {
+		if sym != symbol {
+			others += n
+		}
+	}
+	return others
+}
+
+// syncMirrorLevels - seviyeleri plana göre günceller, Executed ve kazanç geçmişini korur.
+// Planda olmayan emirsiz seviyeleri siler; en büyük seviye index'ini döndürür.
+func syncMirrorLevels(levels *GridLevelMap, plan map[string]GridLevel, tickSize float64) int {
+	maxIndex := 0
+	for name, fresh := range plan {
+		level, ok := levels.Get(name)
+		if !ok {
+			level = fresh
+		}
+		level.Price = snapToTick(fresh.Price, tickSize)
+		level.SizeMultiplier = fresh.SizeMultiplier
+		levels.Set(name, level)
+		maxIndex = max(maxIndex, fresh.Index)
+	}
+	levels.Range(func(name string, level GridLevel) bool {
+		if _, ok := plan[name]; !ok && !level.Executed {
+			levels.Delete(name)
+		}
+		return true
+	})
+	return maxIndex
+}
@@ -0,0 +1,215 @@
+package dnm
+
+import (
+	"math"
+	"sync"
+
+	"github.com/banbox/banbot/config"
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+	ta "github.com/banbox/banta"
+)
+
+// gridLayer - MultiLayerGrid içindeki bağımsız grid katmanı
+type gridLayer struct {
+	name       string
+	spacingATR float64
+	count      int
+	basePrice  float64
+	levels     *GridLevelMap
+}
+
+func newGridLayer(name string, spacingATR float64, count int) *gridLayer {
+	return &gridLayer{name: name, spacingATR: spacingATR, count: count, levels: newGridLevelMap()}
+}
+
+// GridLayerState - MultiLayerGrid katmanının bar sonundaki base fiyatı ve seviyeleri
+type GridLayerState struct {
+	BasePrice float64
+	Levels    map[string]GridLevel
+}
+
+// layerStateKey - jobLayers anahtarı; key "macro_grid_levels" ya da "micro_grid_levels"
+type layerStateKey struct {
+	job *strat.StratJob
+	key string
+}
+
+// jobLayers - strateji dışından katman durumuna erişim için (job, key) -> GridLayerState
+var jobLayers sync.Map
+
+// stateKey - katmanın jobLayers anahtar adı
+func (l *gridLayer) stateKey() string {
+	return l.name + "_grid_levels"
+}
+
+// publish - katmanın güncel görüntüsünü jobLayers'a yazar
+func (l *gridLayer) publish(s *strat.StratJob) {
+	jobLayers.Store(layerStateKey{s, l.stateKey()}, GridLayerState{BasePrice: l.basePrice, Levels: l.levels.Snapshot()})
+}
+
+// GetGridLayerState - MultiLayerGrid job'unda key ("macro_grid_levels" / "micro_grid_levels") katmanının
+// son bar görüntüsü
+func GetGridLayerState(s *strat.StratJob, key string) (GridLayerState, bool) {
+	val, ok := jobLayers.Load(layerStateKey{s, key})
+	if !ok {
+		return GridLayerState{}, false
+	}
+	return val.(GridLayerState), true
+}
+
+// tag - katman adı instance ID olarak kullanılır ("Gmacro_Buy_B1")
+func (l *gridLayer) tag(levelType string, index int) string {
+	return levelTag(l.name, levelType, index)
+}
+
+// openOrders - bu katmanın açık emirleri
+func (l *gridLayer) openOrders(s *strat.StratJob) []*core.Order {
+	return GridOpenOrders(s, gridTagPrefix(l.name))
+}
+
+// update - ilk çağrıda base fiyatı sabitler, seviyeleri ATR aralığına göre günceller.
+// Fiyat en dış seviyenin ötesine geçtiyse ve katmanın açık emri yoksa (idle) base güncel fiyata taşınır
+// ve true döner; emri açık katman pozisyonları kapanana kadar yerinde kalır.
+func (l *gridLayer) update(price, atr, tickSize float64, idle bool) bool {
+	spacing := atr * l.spacingATR
+	moved := false
+	if l.basePrice == 0 {
+		l.basePrice = price
+	} else if idle && spacing > 0 && math.Abs(price-l.basePrice) > spacing*float64(l.count) {
+		l.basePrice = price
+		l.levels.ResetLayout()
+		moved = true
+	}
+	updateGridLevels(l.levels, l.basePrice, spacing, l.count, tickSize, 0, 0, nil)
+	return moved
+}
+
+// execute - tetiklenen seviyeler için emir açar. openTrades katmanlar arası ortak sayaçtır; her başarılı
+// emirde artırılır ve maxTrades'e ulaşınca yeni emir açılmaz
+func (l *gridLayer) execute(s *strat.StratJob, low, high, amount float64, openTrades *int, maxTrades int) {
+	for i := 1; i <= l.count; i++ {
+		for _, levelType := range []string{LevelBuy, LevelSell} {
+			name := levelName(levelType, i)
+			level, ok := l.levels.Get(name)
+			if !ok || !level.Active || level.Executed {
+				continue
+			}
+			triggered := low <= level.Price
+			if levelType == LevelSell {
+				triggered = high >= level.Price
+			}
+			if !triggered || *openTrades >= maxTrades {
+				continue
+			}
+			size := amount * level.SizeMultiplier
+			req := &strat.EnterReq{
+				Tag:    l.tag(levelType, i),
+				Short:  levelType == LevelSell,
+				Amount: size,
+			}
+			if err := s.OpenOrder(req); err != nil {
+				s.Infof("%s grid %s level %d not executed: %v", l.name, levelType, i, err)
+				continue
+			}
+			level.Executed = true
+			l.levels.Set(name, level)
+			*openTrades++
+			s.Infof("%s grid %s level %d executed: Price=%.4f, Size=%.4f", l.name, levelType, i, level.Price, size)
+		}
+	}
+}
+
+// MultiLayerGrid - farklı ölçeklerde iki bağımsız grid: geniş aralıklı "macro" ve sık aralıklı "micro".
+// İki katman max_concurrent_trades limitini paylaşır; macro katman stres tespit ettiğinde micro durur.
+func MultiLayerGrid(pol *config.RunPolicyConfig) *strat.TradeStrat {
+
+	atrPeriod := int(pol.Def("atr_period", 14, core.PNorm(5, 50)))
+	tickSize := float64(pol.Def("tick_size", 0.0001))
+	macroSpacingATR := float64(pol.Def("macro_spacing_atr", 3.0, core.PNorm(2.0, 5.0)))
+	macroLevels := int(pol.Def("macro_levels", 3, core.PNorm(2, 5)))
+	microSpacingATR := float64(pol.Def("micro_spacing_atr", 0.5, core.PNorm(0.2, 1.0)))
+	microLevels := int(pol.Def("micro_levels", 10, core.PNorm(5, 20)))
+	useStochRSI := bool(pol.Def("use_stochrsi", false)) // stres filtresinde düz RSI yerine StochRSI
+
+	// Risk Management
+	initialCapital := float64(pol.Def("initial_capital", 10000.0))
+	maxSinglePosition := float64(pol.Def("max_single_position", 5.0, core.PNorm(1.0, 10.0)))
+	maxConcurrentTrades := int(pol.Def("max_concurrent_trades", 8, core.PNorm(3, 20)))
+	stopLossATR := float64(pol.Def("stop_loss_atr", 2.0, core.PNorm(1.0, 5.0)))
+	takeProfitATR := float64(pol.Def("take_profit_atr", 3.0, core.PNorm(1.5, 8.0)))
+
+	macro := newGridLayer("macro", macroSpacingATR, macroLevels)
+	micro := newGridLayer("micro", microSpacingATR, microLevels)
+	var totalRealizedPnl float64 = 0
+
+	return &strat.TradeStrat{
+		WarmupNum:     gridWarmupNum,
+		StopEnterBars: validateStopEnterBars(pol),
+
+		OnBar: func(s *strat.StratJob) {
+			e := s.Env
+
+			minBars := max(atrPeriod, 20)
+			if e.Close.Len() < minBars || e.High.Len() < minBars || e.Low.Len() < minBars {
+				return
+			}
+
+			currentPrice := e.Close.Last(0)
+			currentHigh := e.High.Last(0)
+			currentLow := e.Low.Last(0)
+			atrValue := ta.ATR(e.High, e.Low, e.Close, atrPeriod)
+
+			// Macro stres: aşırı RSI (ya da StochRSI %K ve %D) veya Bollinger sıkışması
+			bbUpper, _, bbLower := ta.BOLL(e.Close, 20, 2.0)
+			bbSqueeze := (bbUpper-bbLower)/ta.SMA(e.Close, 20) < 0.05
+			var extreme bool
+			if useStochRSI {
+				k, d := computeStochRSI(e.Close, 14, 14, 3, 3)
+				extreme = (k > 80 && d > 80) || (k < 20 && d < 20)
+			} else {
+				rsiValue := ta.RSI(e.Close, 14)
+				extreme = rsiValue > 80 || rsiValue < 20
+			}
+			macroStress := bbSqueeze || extreme
+
+			for _, layer := range []*gridLayer{macro, micro} {
+				if layer.update(currentPrice, atrValue, tickSize, len(layer.openOrders(s)) == 0) {
+					s.Infof("%s grid recentered at %.4f", layer.name, layer.basePrice)
+				}
+			}
+
+			// Ortak eşzamanlı işlem limiti; yalnızca iki katmanın emirleri sayılır
+			openTrades := len(macro.openOrders(s)) + len(micro.openOrders(s))
+
+			macroSize := initialCapital * (maxSinglePosition / 100) / float64(macroLevels)
+			microSize := initialCapital * (maxSinglePosition / 100) / float64(microLevels)
+			macro.execute(s, currentLow, currentHigh, macroSize, &openTrades, maxConcurrentTrades)
+			if !macroStress {
+				micro.execute(s, currentLow, currentHigh, microSize, &openTrades, maxConcurrentTrades)
+			}
+
+			for _, layer := range []*gridLayer{macro, micro} {
+				closed := manageTradingOrders(s, layer.levels, layer.name, atrValue, stopLossATR, takeProfitATR, TPTypeATR, 0, false, nil, nil)
+				for _, trade := range closed {
+					totalRealizedPnl += trade.PnL
+				}
+				releaseClosedLevels(s, layer.levels, layer.name, closed) // kapanan seviye yeniden tetiklenebilir
+				layer.publish(s)
+			}
+
+			if e.BarIndex%100 == 0 {
+				macroOpen := len(macro.openOrders(s))
+				microOpen := len(micro.openOrders(s))
+				s.Infof("Multi-Layer Grid: Price=%.4f, Macro Base=%.4f (%d open), Micro Base=%.4f (%d open), Stress=%v, PnL=%.2f",
+					currentPrice, macro.basePrice, macroOpen, micro.basePrice, microOpen, macroStress, totalRealizedPnl)
+			}
+		},
+
+		OnShutDown: func(s *strat.StratJob) {
+			for _, layer := range []*gridLayer{macro, micro} {
+				jobLayers.Delete(layerStateKey{s, layer.stateKey()})
+			}
+		},
+	}
+}
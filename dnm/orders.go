@@ -0,0 +1,278 @@
+package dnm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+)
+
+// Take-profit tipleri (tp_type)
+const (
+	TPTypeATR       = 1 // giriş ± ATR * take_profit_atr
+	TPTypeNextLevel = 2 // sonraki grid seviyesi
+	TPTypeFixedPct  = 3 // giriş ± take_profit_pct
+)
+
+// Emir tipleri (order_type)
+const (
+	OrderTypeMarket = 1
+	OrderTypeLimit  = 2 // limit fiyatı seviye fiyatı
+)
+
+// pendingLimit - dolmayı bekleyen limit grid emri
+type pendingLimit struct {
+	req       *strat.EnterReq
+	placedBar int
+}
+
+// GridOpenOrders - tag'i gridPrefix ile başlayan tüm açık emirler (önce long, sonra short)
+func GridOpenOrders(s *strat.StratJob, gridPrefix string) []*core.Order {
+	return append(GridLongOrders(s, gridPrefix), GridShortOrders(s, gridPrefix)...)
+}
+
+// GridLongOrders - tag'i gridPrefix ile başlayan long emirler
+func GridLongOrders(s *strat.StratJob, gridPrefix string) []*core.Order {
+	return filterOrdersByPrefix(s.LongOrders, gridPrefix)
+}
+
+// GridShortOrders - tag'i gridPrefix ile başlayan short emirler
+func GridShortOrders(s *strat.StratJob, gridPrefix string) []*core.Order {
+	return filterOrdersByPrefix(s.ShortOrders, gridPrefix)
+}
+
+func filterOrdersByPrefix(orders []*core.Order, prefix string) []*core.Order {
+	res := make([]*core.Order, 0, len(orders))
+	for _, order := range orders {
+		if strings.HasPrefix(order.Tag, prefix) {
+			res = append(res, order)
+		}
+	}
+	return res
+}
+
+// shouldThrottle - bu barda açılan seviye sayısı limite ulaştı mı? (limit <= 0 ise sınırsız)
+// Tetiklenip açılmayan seviyeler Executed işaretlenmediği için sonraki barda tekrar denenir.
+func shouldThrottle(count, limit int) bool {
+	return limit > 0 && count >= limit
+}
+
+// positionAged - entryBarIndex'te açılan pozisyon maxHoldBars'ı doldurdu mu? (maxHoldBars <= 0 ise kapalı)
+func positionAged(barIndex, entryBarIndex, maxHoldBars int) bool {
+	return maxHoldBars > 0 && barIndex-entryBarIndex >= maxHoldBars
+}
+
+// isSizeError - hata yetersiz marjin/bakiye ya da boyut kısıtından mı kaynaklanıyor?
+func isSizeError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, key := range []string{"insufficient", "margin", "balance", "min amount", "min cost", "too small", "size"} {
+		if strings.Contains(msg, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapitalRequest - sermaye yetersizliğinden reddedilen grid emrinin özeti
+type CapitalRequest struct {
+	Tag              string
+	RequiredCost     float64
+	AvailableCapital float64
+}
+
+// logCapitalShortfall - GridConfig.OnCapitalShortfall verilmediğinde kullanılan varsayılan hook
+func logCapitalShortfall(s *strat.StratJob, req CapitalRequest) {
+	s.Infof("Capital shortfall for %s: required %.2f, available %.2f", req.Tag, req.RequiredCost, req.AvailableCapital)
+}
+
+// isCapitalError - hata yetersiz bakiye/marjinden mi kaynaklanıyor?
+func isCapitalError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, key := range []string{"insufficient", "margin", "balance"} {
+		if strings.Contains(msg, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyCapitalShortfall - sermaye hatasında hook'u (nil ise logCapitalShortfall) çağırır
+func notifyCapitalShortfall(s *strat.StratJob, hook func(*strat.StratJob, CapitalRequest), req *strat.EnterReq,
+	err error, price, available float64) {
+	if !isCapitalError(err) {
+		return
+	}
+	if hook == nil {
+		hook = logCapitalShortfall
+	}
+	hook(s, CapitalRequest{
+		Tag:              req.Tag,
+		RequiredCost:     req.Amount * price,
+		AvailableCapital: available,
+	})
+}
+
+// retryOrder - boyut/marjin kaynaklı reddedilen emri yarı boyutla en fazla maxRetries kez tekrar dener.
+// Emir CostRate ile verilmişse CostRate, aksi halde Amount yarıya indirilir.
+func retryOrder(s *strat.StratJob, req *strat.EnterReq, err error, maxRetries int) error {
+	for attempt := 1; attempt <= maxRetries && err != nil && isSizeError(err); attempt++ {
+		s.Infof("Order %s rejected: %v", req.Tag, err)
+		if req.CostRate > 0 {
+			req.CostRate /= 2
+		} else {
+			req.Amount /= 2
+		}
+		err = s.OpenOrder(req)
+		if err != nil {
+			s.Infof("Order %s retry %d failed: %v", req.Tag, attempt, err)
+		} else {
+			s.Infof("Order %s retry %d succeeded with reduced size", req.Tag, attempt)
+		}
+	}
+	return err
+}
+
+// attemptPartialFill - sermaye, istenen maliyetin minPartialPct'sinden fazlasını karşılıyorsa
+// emri mevcut sermayenin %95'i kadar küçültüp tekrar dener. Başarısız olursa emir boyutu geri alınır.
+func attemptPartialFill(s *strat.StratJob, req *strat.EnterReq, price, availableCapital, minPartialPct float64) error {
+	required := req.Amount * price
+	if required <= 0 || availableCapital <= 0 {
+		return fmt.Errorf("no capital available for %s", req.Tag)
+	}
+	ratio := availableCapital / required
+	if ratio >= 1 || ratio <= minPartialPct/100 {
+		return fmt.Errorf("available capital covers %.1f%% of %s, partial fill not applicable", ratio*100, req.Tag)
+	}
+	amount, costRate := req.Amount, req.CostRate
+	if req.CostRate > 0 {
+		req.CostRate *= ratio * 0.95
+	} else {
+		req.Amount *= ratio * 0.95
+	}
+	if err := s.OpenOrder(req); err != nil {
+		req.Amount, req.CostRate = amount, costRate
+		return err
+	}
+	s.Infof("Order %s partially filled: %.1f%% of requested size", req.Tag, ratio*95)
+	return nil
+}
+
+// cancelStaleLimitOrders - fiyat seviyeden 2*spacing'den fazla uzaklaşmışsa
+// dolmamış grid emrini iptal eder. Seviye pasifleşir, rebalance'ta yeniden oluşturulabilir.
+func cancelStaleLimitOrders(s *strat.StratJob, levels *GridLevelMap, instanceID string, currentPrice, spacing float64) {
+	if spacing <= 0 {
+		return
+	}
+	for _, order := range GridOpenOrders(s, gridTagPrefix(instanceID)) {
+		if order.Status == core.OdStatusFull {
+			continue
+		}
+		level, ok := levelForOrder(levels, order, instanceID)
+		if !ok || math.Abs(level.Price-currentPrice) <= 2*spacing {
+			continue
+		}
+		s.CloseOrders(&strat.ExitReq{
+			Tag:    "stale_" + order.Tag,
+			Orders: []*core.Order{order},
+		})
+		level.Active = false
+		level.Executed = false
+		levels.Set(level.Name, level)
+		s.Infof("Stale order %s cancelled: level %.4f is %.4f away from price", order.Tag,
+			level.Price, math.Abs(level.Price-currentPrice))
+	}
+}
+
+// openLevelNames - bu bar kapatılanlar dışında açık emri kalan seviyelerin adları
+func openLevelNames(s *strat.StratJob, instanceID string, closed []closedTrade) map[string]bool {
+	done := make(map[*core.Order]bool, len(closed))
+	for _, trade := range closed {
+		done[trade.Order] = true
+	}
+	stillOpen := make(map[string]bool)
+	for _, order := range GridOpenOrders(s, gridTagPrefix(instanceID)) {
+		if done[order] {
+			continue
+		}
+		if name, ok := levelNameFromTag(order.Tag, instanceID); ok {
+			stillOpen[name] = true
+		}
+	}
+	return stillOpen
+}
+
+// releaseClosedLevels - bu bar kapatılan emirlerin seviyelerini yeniden emir açabilir hale getirir.
+// Seviyenin başka açık emri kalmadıysa Executed sıfırlanır; Active'e dokunulmaz, böylece
+// kazanma oranı ya da stale emir nedeniyle pasifleştirilmiş seviyeler pasif kalır.
+func releaseClosedLevels(s *strat.StratJob, levels *GridLevelMap, instanceID string, closed []closedTrade) {
+	stillOpen := openLevelNames(s, instanceID, closed)
+	for _, trade := range closed {
+		if trade.Level == "" || stillOpen[trade.Level] {
+			continue
+		}
+		if level, ok := levels.Get(trade.Level); ok && level.Executed {
+			level.Executed = false
+			levels.Set(trade.Level, level)
+		}
+	}
+}
+
+// isProfitableToClose - emrin güncel fiyattaki açık getirisi (%) giriş ve çıkış komisyonunu
+// (2 * feePct) karşılıyor mu? Karşılamayan gönüllü çıkışlar komisyonla zarara döner.
+func isProfitableToClose(od *core.Order, currentPrice, feePct float64) bool {
+	if od.AvgPrice <= 0 {
+		return false
+	}
+	gainPct := (currentPrice - od.AvgPrice) / od.AvgPrice * 100
+	if od.Short {
+		gainPct = -gainPct
+	}
+	return gainPct >= 2*feePct
+}
+
+// applySlippage - backtest'te dolum belirsizliğini modellemek için fiyata
+// ortalaması 0, standart sapması price*slippagePct/100 olan normal sapma ekler
+func applySlippage(price, slippagePct float64, rng *rand.Rand) float64 {
+	if slippagePct <= 0 || rng == nil {
+		return price
+	}
+	return price + rng.NormFloat64()*price*slippagePct/100
+}
+
+// reconcilePendingLimits - bekleyen limit emirlerin durumunu günceller. Dolanlar listeden çıkar,
+// maxBars bardan uzun süre dolmayanlar iptal edilip seviyesi yeniden tetiklenebilir hale getirilir
+// (maxBars 0 ise süre sınırı yok). Başka yerden iptal edilen emirler de listeden çıkarılır.
+func reconcilePendingLimits(s *strat.StratJob, pending map[string]pendingLimit, levels *GridLevelMap, instanceID string,
+	barIndex, maxBars int) {
+	if len(pending) == 0 {
+		return
+	}
+	orders := make(map[string]*core.Order)
+	for _, order := range GridOpenOrders(s, gridTagPrefix(instanceID)) {
+		orders[order.Tag] = order
+	}
+	for name, item := range pending {
+		order, ok := orders[item.req.Tag]
+		switch {
+		case !ok:
+			delete(pending, name)
+		case order.Status == core.OdStatusFull:
+			delete(pending, name)
+			s.Infof("Limit order %s filled at %.4f", order.Tag, order.AvgPrice)
+		case maxBars > 0 && barIndex-item.placedBar >= maxBars:
+			s.CloseOrders(&strat.ExitReq{
+				Tag:    "expired_" + order.Tag,
+				Orders: []*core.Order{order},
+			})
+			delete(pending, name)
+			if level, ok := levels.Get(name); ok {
+				level.Executed = false
+				levels.Set(name, level)
+			}
+			s.Infof("Limit order %s cancelled after %d bars unfilled", order.Tag, barIndex-item.placedBar)
+		}
+	}
+}
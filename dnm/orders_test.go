@@ -0,0 +1,226 @@
+package dnm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+)
+
+// benchOrders - yarısı grid tag'li 200 emirlik iş
+func benchOrders() *strat.StratJob {
+	s := &strat.StratJob{}
+	for i := 0; i < 100; i++ {
+		s.LongOrders = append(s.LongOrders,
+			&core.Order{Tag: levelTag("0", LevelBuy, i%maxGridLevels+1)},
+			&core.Order{Tag: fmt.Sprintf("ma_cross_%d", i)})
+	}
+	return s
+}
+
+func BenchmarkGridLongOrders(b *testing.B) {
+	s := benchOrders()
+	prefix := gridTagPrefix("0")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = GridLongOrders(s, prefix)
+	}
+}
+
+func BenchmarkInlineOrderFilter(b *testing.B) {
+	s := benchOrders()
+	prefix := gridTagPrefix("0")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var res []*core.Order
+		for _, order := range s.LongOrders {
+			if strings.HasPrefix(order.Tag, prefix) {
+				res = append(res, order)
+			}
+		}
+		_ = res
+	}
+}
+
+// İki instance aynı işte aynı seviye adlarını kullanır; biri diğerinin emirlerini ve seviyelerini görmemeli
+func TestInstanceOrdersIsolated(t *testing.T) {
+	a0 := &core.Order{Tag: levelTag("0", LevelBuy, 1)}
+	a1 := &core.Order{Tag: levelTag("1", LevelBuy, 1)}
+	s1 := &core.Order{Tag: levelTag("1", LevelSell, 1), Short: true}
+	s := &strat.StratJob{LongOrders: []*core.Order{a0, a1}, ShortOrders: []*core.Order{s1}}
+
+	if got := GridOpenOrders(s, gridTagPrefix("0")); len(got) != 1 || got[0] != a0 {
+		t.Errorf("instance 0 orders = %v, want only %s", got, a0.Tag)
+	}
+	if got := GridOpenOrders(s, gridTagPrefix("1")); len(got) != 2 {
+		t.Errorf("instance 1 has %d orders, want 2", len(got))
+	}
+	if _, ok := levelNameFromTag(a1.Tag, "0"); ok {
+		t.Errorf("instance 0 resolved foreign tag %s", a1.Tag)
+	}
+	if _, ok := levelNameFromTag(levelTag("10", LevelBuy, 1), "1"); ok {
+		t.Error("instance 1 resolved a tag of instance 10")
+	}
+
+	newLevels := func() *GridLevelMap {
+		levels := newGridLevelMap()
+		levels.Set("B1", GridLevel{Name: "B1", Type: LevelBuy, Price: 99, Active: true, Executed: true})
+		return levels
+	}
+	levels0, levels1 := newLevels(), newLevels()
+	closed := []closedTrade{{Order: a0, Level: "B1"}}
+	releaseClosedLevels(s, levels0, "0", closed)
+	releaseClosedLevels(s, levels1, "1", closed) // instance 1'in B1 emri (a1) hâlâ açık
+	if level, _ := levels0.Get("B1"); level.Executed {
+		t.Error("instance 0 B1 not released after its order closed")
+	}
+	if level, _ := levels1.Get("B1"); !level.Executed {
+		t.Error("instance 1 B1 released by instance 0's close")
+	}
+}
+
+func TestCapitalShortfallHook(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCall bool
+	}{
+		{name: "insufficient balance", err: errors.New("insufficient balance for order"), wantCall: true},
+		{name: "margin", err: errors.New("Margin is not enough"), wantCall: true},
+		{name: "unrelated error", err: errors.New("symbol not found")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Her denemede aynı hatayla reddeden emir
+			openOrder := func(*strat.EnterReq) error { return tt.err }
+			var calls []CapitalRequest
+			hook := func(_ *strat.StratJob, req CapitalRequest) { calls = append(calls, req) }
+
+			req := &strat.EnterReq{Tag: levelTag("0", LevelBuy, 1), Amount: 2}
+			for attempt := 0; attempt < 3; attempt++ {
+				if err := openOrder(req); err != nil {
+					notifyCapitalShortfall(&strat.StratJob{}, hook, req, err, 50, 60)
+				}
+			}
+			if !tt.wantCall {
+				if len(calls) != 0 {
+					t.Errorf("hook called %d times for a non-capital error", len(calls))
+				}
+				return
+			}
+			if len(calls) != 3 {
+				t.Fatalf("hook called %d times, want 3", len(calls))
+			}
+			want := CapitalRequest{Tag: req.Tag, RequiredCost: 100, AvailableCapital: 60}
+			if calls[0] != want {
+				t.Errorf("hook got %+v, want %+v", calls[0], want)
+			}
+		})
+	}
+}
+
+func TestComputeTP(t *testing.T) {
+	levels := map[string]GridLevel{
+		"B1": {Name: "B1", Type: LevelBuy, Price: 99},
+		"B2": {Name: "B2", Type: LevelBuy, Price: 98},
+		"S1": {Name: "S1", Type: LevelSell, Price: 101},
+		"S2": {Name: "S2", Type: LevelSell, Price: 102},
+	}
+	long := &core.Order{AvgPrice: 99}
+	short := &core.Order{AvgPrice: 101, Short: true}
+	tests := []struct {
+		name   string
+		order  *core.Order
+		tpType int
+		want   float64
+	}{
+		{name: "atr long", order: long, tpType: TPTypeATR, want: 102},
+		{name: "atr short", order: short, tpType: TPTypeATR, want: 98},
+		{name: "next level long", order: long, tpType: TPTypeNextLevel, want: 101},
+		{name: "next level short", order: short, tpType: TPTypeNextLevel, want: 99},
+		{name: "next level falls back to atr", order: &core.Order{AvgPrice: 105}, tpType: TPTypeNextLevel, want: 108},
+		{name: "fixed pct long", order: long, tpType: TPTypeFixedPct, want: 99 * 1.02},
+		{name: "fixed pct short", order: short, tpType: TPTypeFixedPct, want: 101 * 0.98},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloat(t, "tp", computeTP(tt.order, tt.tpType, levels, 1.5, 2, 2), tt.want)
+		})
+	}
+}
+
+func TestAttemptPartialFill(t *testing.T) {
+	tests := []struct {
+		name       string
+		available  float64 // istenen maliyet 200
+		costRate   float64
+		wantErr    bool
+		wantAmount float64
+		wantRate   float64
+	}{
+		{name: "capital covers 60%", available: 120, wantAmount: 2 * 0.6 * 0.95},
+		{name: "cost rate reduced instead of amount", available: 120, costRate: 0.5, wantAmount: 2, wantRate: 0.5 * 0.6 * 0.95},
+		{name: "below minimum partial", available: 80, wantErr: true, wantAmount: 2},
+		{name: "full capital", available: 250, wantErr: true, wantAmount: 2},
+		{name: "no capital", available: 0, wantErr: true, wantAmount: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &strat.EnterReq{Tag: levelTag("0", LevelBuy, 1), Amount: 2, CostRate: tt.costRate}
+			err := attemptPartialFill(&strat.StratJob{}, req, 100, tt.available, 50)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			assertFloat(t, "Amount", req.Amount, tt.wantAmount)
+			assertFloat(t, "CostRate", req.CostRate, tt.wantRate)
+		})
+	}
+}
+
+// Beş seviyeyi geçen gap barında yalnızca iki emir açılır, kalanlar sonraki bara kalır
+func TestMaxLevelsPerBarThrottle(t *testing.T) {
+	levels := newGridLevelMap()
+	updateGridLevels(levels, 100, 1, 5, 0, 0, 0, nil)
+	opened := func(low, high float64) []string {
+		var names []string
+		levels.Range(func(name string, level GridLevel) bool {
+			if level.Type != LevelBuy || level.Executed || !isLevelTriggered(level.Price, low, high, 0, 0) {
+				return true
+			}
+			if shouldThrottle(len(names), 2) {
+				return false
+			}
+			level.Executed = true
+			levels.Set(name, level)
+			names = append(names, name)
+			return true
+		})
+		return names
+	}
+	if got := opened(94.5, 100); len(got) != 2 {
+		t.Fatalf("gap bar opened %v, want 2 orders", got)
+	}
+	if got := opened(94.5, 100); len(got) != 2 {
+		t.Errorf("next bar opened %v, want 2 deferred levels", got)
+	}
+	if got := opened(94.5, 100); len(got) != 1 {
+		t.Errorf("third bar opened %v, want the last level", got)
+	}
+	if shouldThrottle(100, 0) {
+		t.Error("limit 0 must disable the throttle")
+	}
+}
+
+// 100. barda açılan emir max_hold_bars=50 ile 150. barda kapanır
+func TestPositionAging(t *testing.T) {
+	closedAt := firstTrue(300, func(bar int) bool { return bar >= 100 && positionAged(bar, 100, 50) })
+	if closedAt != 150 {
+		t.Errorf("aged exit at bar %d, want 150", closedAt)
+	}
+	if positionAged(1000, 100, 0) {
+		t.Error("max_hold_bars=0 must disable aging")
+	}
+}
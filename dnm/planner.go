@@ -0,0 +1,90 @@
+package dnm
+
+import "math"
+
+// historicalFillRates - seviye aralığına (%) göre seviye başına günlük dolum varsayımı. Orta volatiliteli
+// kripto paritelerinin geçmişine dayanan kaba değerlerdir; ara aralıklar log-log interpolasyonla,
+// tablo dışındakiler en yakın uçtan ölçeklenerek bulunur.
+var historicalFillRates = []struct {
+	spacingPct  float64
+	fillsPerDay float64
+}{
+	{0.25, 40},
+	{0.5, 12},
+	{1, 3.5},
+	{2, 1},
+	{5, 0.2},
+	{10, 0.05},
+}
+
+// CapitalEstimate - grid kurulmadan önce sermaye planlaması için tahminler (quote cinsinden)
+type CapitalEstimate struct {
+	MinRequired          float64 // tüm alış seviyelerinin maliyeti + giriş komisyonu
+	Recommended          float64 // MinRequired + satış seviyeleri teminatı + en kötü drawdown
+	WorstCaseDrawdown    float64 // tüm alışlar dolar, hiçbiri kapanmaz, fiyat en alt seviyede kalır
+	ExpectedMonthlyTurns float64 // ayda beklenen tam (alış+satış) grid döngüsü
+}
+
+// EstimateGridCapital - base fiyat etrafında spacingPct aralıklı buyCount alış ve sellCount satış
+// seviyesi için gereken sermayeyi tahmin eder. Emir boyutu DCA ayarından gelir: ilk seviye 1 birim
+// base asset, her uzaklaşan seviye dcaMultiplier katı (1 = eşit boyut). Tutarlar emir miktarıyla
+// doğrusal ölçeklenir; ilk seviyesi q birim olan grid için sermaye alanları q ile çarpılır.
+// feePct tek yön komisyondur.
+func EstimateGridCapital(basePrice, spacingPct float64, buyCount, sellCount int,
+	dcaMultiplier, feePct float64) CapitalEstimate {
+	var res CapitalEstimate
+	if basePrice <= 0 || spacingPct <= 0 {
+		return res
+	}
+	if dcaMultiplier <= 0 {
+		dcaMultiplier = 1
+	}
+	sizing := dcaSizing(dcaMultiplier)
+	feeRate := feePct / 100
+
+	// Alış tarafı: maliyet ve en alt seviyeye göre açık zarar
+	lowest := basePrice
+	var buyAmounts, buyPrices []float64
+	for i := 1; i <= buyCount; i++ {
+		price := basePrice * (1 - spacingPct/100*float64(i))
+		if price <= 0 {
+			break
+		}
+		amount := sizing(i)
+		buyAmounts = append(buyAmounts, amount)
+		buyPrices = append(buyPrices, price)
+		res.MinRequired += price * amount * (1 + feeRate)
+		lowest = price
+	}
+	for i, price := range buyPrices {
+		res.WorstCaseDrawdown += (price-lowest)*buyAmounts[i] + price*buyAmounts[i]*feeRate
+	}
+
+	// Satış tarafı: short teminatı
+	sellCost := 0.0
+	for i := 1; i <= sellCount; i++ {
+		price := basePrice * (1 + spacingPct/100*float64(i))
+		sellCost += price * sizing(i) * (1 + feeRate)
+	}
+	res.Recommended = res.MinRequired + sellCost + res.WorstCaseDrawdown
+
+	// Tam döngü bir alış ve bir satış dolumu ister
+	res.ExpectedMonthlyTurns = expectedFillsPerDay(spacingPct) / 2 * 30
+	return res
+}
+
+// expectedFillsPerDay - historicalFillRates'ten spacingPct için seviye başına günlük dolum
+func expectedFillsPerDay(spacingPct float64) float64 {
+	rates := historicalFillRates
+	if spacingPct <= 0 {
+		return 0
+	}
+	i := 1
+	for i < len(rates)-1 && spacingPct > rates[i].spacingPct {
+		i++
+	}
+	lo, hi := rates[i-1], rates[i]
+	// log(dolum) ile log(aralık) arasında doğrusal; uç aralıklarda eğim korunarak dışa taşınır
+	slope := math.Log(hi.fillsPerDay/lo.fillsPerDay) / math.Log(hi.spacingPct/lo.spacingPct)
+	return lo.fillsPerDay * math.Pow(spacingPct/lo.spacingPct, slope)
+}
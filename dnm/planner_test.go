@@ -0,0 +1,55 @@
+package dnm
+
+import "testing"
+
+func TestEstimateGridCapital(t *testing.T) {
+	tests := []struct {
+		name                      string
+		buyCount, sellCount       int
+		dcaMultiplier             float64
+		wantMin, wantDD, wantRecm float64
+	}{
+		// Alışlar 99 ve 98'de 1'er birim; en alt seviye 98
+		{name: "equal sizes", buyCount: 2, sellCount: 2, dcaMultiplier: 1,
+			wantMin: 197, wantDD: 1, wantRecm: 197 + 203 + 1},
+		// DCA 2x: 99'da 1, 98'de 2 birim
+		{name: "dca doubling", buyCount: 2, sellCount: 1, dcaMultiplier: 2,
+			wantMin: 99 + 196, wantDD: 1, wantRecm: 295 + 101 + 1},
+		{name: "no levels", dcaMultiplier: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateGridCapital(100, 1, tt.buyCount, tt.sellCount, tt.dcaMultiplier, 0)
+			assertFloat(t, "MinRequired", got.MinRequired, tt.wantMin)
+			assertFloat(t, "WorstCaseDrawdown", got.WorstCaseDrawdown, tt.wantDD)
+			assertFloat(t, "Recommended", got.Recommended, tt.wantRecm)
+		})
+	}
+	if got := EstimateGridCapital(0, 1, 2, 2, 1, 0.1); got != (CapitalEstimate{}) {
+		t.Errorf("zero base price: %+v, want zero estimate", got)
+	}
+}
+
+func TestExpectedFillsPerDay(t *testing.T) {
+	tests := []struct {
+		spacingPct, want float64
+	}{
+		{0, 0},
+		{1, 3.5},                // tablo değeri
+		{5, 0.2},                // tablo değeri
+		{0.125, 40.0 * 40 / 12}, // ilk aralığın eğimiyle dışa taşınır
+	}
+	for _, tt := range tests {
+		assertFloat(t, "expectedFillsPerDay", expectedFillsPerDay(tt.spacingPct), tt.want)
+	}
+	// Geniş aralık daha seyrek dolar
+	prev := expectedFillsPerDay(0.1)
+	for _, spacing := range []float64{0.3, 0.7, 1.5, 3, 7, 15} {
+		got := expectedFillsPerDay(spacing)
+		if got >= prev {
+			t.Errorf("fills at %.1f%% = %.4f, not below %.4f", spacing, got, prev)
+		}
+		prev = got
+	}
+	assertFloat(t, "monthly turns at 1%", EstimateGridCapital(100, 1, 1, 1, 1, 0).ExpectedMonthlyTurns, 3.5/2*30)
+}
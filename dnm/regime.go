@@ -0,0 +1,231 @@
+package dnm
+
+import (
+	"math"
+
+	ta "github.com/banbox/banta"
+)
+
+// MarketRegime - piyasa fazı
+type MarketRegime int
+
+const (
+	RegimeRanging  MarketRegime = iota // düşük volatilite, ortalamaya dönüş
+	RegimeTrending                     // kalıcı yön, grid zarar biriktirir
+	RegimeVolatile                     // yüksek volatilite, seviyeler genişletilmeli
+)
+
+// Rejim sınıflandırma eşikleri
+const (
+	regimeHighATRPct       = 2.0  // bar ATR'si fiyatın %'si olarak
+	regimeTrendStrengthPct = 2.0  // fiyatın trend çizgisine uzaklığı (%)
+	regimeHurstTrending    = 0.55 // üstü kalıcı (trend)
+	regimeHurstWindow      = 100
+)
+
+func (r MarketRegime) String() string {
+	switch r {
+	case RegimeTrending:
+		return "trending"
+	case RegimeVolatile:
+		return "volatile"
+	}
+	return "ranging"
+}
+
+// classifyRegime - ATR (%), Hurst üssü ve trend gücüne (%) göre piyasa fazı:
+// kalıcı (Hurst > 0.55) ve güçlü trend trending, düşük ATR ve Hurst < 0.5 ranging,
+// yüksek ATR ve Hurst > 0.5 volatile. Hiçbir kural uymazsa ATR seviyesi belirler.
+func classifyRegime(atrPct, hurstExp, trendStrength float64) MarketRegime {
+	if hurstExp > regimeHurstTrending && math.Abs(trendStrength) > regimeTrendStrengthPct {
+		return RegimeTrending
+	}
+	if atrPct >= regimeHighATRPct {
+		return RegimeVolatile
+	}
+	return RegimeRanging
+}
+
+// hurstExponent - log getirilerin rescaled range (R/S) analiziyle Hurst üssü.
+// 0.5 rastgele yürüyüş, üstü trend, altı ortalamaya dönüş. Veri yetersizse NaN döner.
+func hurstExponent(closes []float64) float64 {
+	if len(closes) < 33 {
+		return math.NaN()
+	}
+	rets := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			return math.NaN()
+		}
+		rets = append(rets, math.Log(closes[i]/closes[i-1]))
+	}
+
+	// Pencere boyutu ikiye katlanarak log(R/S) ~ H * log(n) regresyonu
+	var xs, ys []float64
+	for size := 8; size <= len(rets)/2; size *= 2 {
+		total, chunks := 0.0, 0
+		for start := 0; start+size <= len(rets); start += size {
+			if rs := rescaledRange(rets[start : start+size]); rs > 0 {
+				total += rs
+				chunks++
+			}
+		}
+		if chunks > 0 {
+			xs = append(xs, math.Log(float64(size)))
+			ys = append(ys, math.Log(total/float64(chunks)))
+		}
+	}
+	if len(xs) < 2 {
+		return math.NaN()
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	var cov, varX float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if varX == 0 {
+		return math.NaN()
+	}
+	return cov / varX
+}
+
+// rescaledRange - serinin kümülatif sapma aralığının standart sapmaya oranı (R/S)
+func rescaledRange(values []float64) float64 {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var cum, hi, lo, sq float64
+	for _, v := range values {
+		cum += v - mean
+		hi = math.Max(hi, cum)
+		lo = math.Min(lo, cum)
+		sq += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(sq / float64(len(values)))
+	if std == 0 {
+		return 0
+	}
+	return (hi - lo) / std
+}
+
+// Approximate entropy ayarları: m gömme boyutu, r getiri standart sapmasına oranla tolerans
+const (
+	entropyEmbedding = 2
+	entropyTolerance = 0.2
+	entropyWindow    = 100
+)
+
+// computeApproximateEntropy - son entropyWindow barın getirileri üzerinde Pincus ApEn(m, r).
+// r getirilerin standart sapmasıyla çarpılarak mutlak toleransa çevrilir. Düzenli (tekrar eden)
+// serilerde 0'a yakın, gürültüde yüksek değer verir. Veri yetersizse NaN döner.
+func computeApproximateEntropy(close *ta.Series, m int, r float64) float64 {
+	closes := seriesWindow(close, entropyWindow+1)
+	if m < 1 || len(closes) < m+3 {
+		return math.NaN()
+	}
+	returns := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 {
+			return math.NaN()
+		}
+		returns = append(returns, closes[i]/closes[i-1]-1)
+	}
+	_, std := meanStd(returns)
+	if std == 0 {
+		return 0
+	}
+	tol := r * std
+	return apEnPhi(returns, m, tol) - apEnPhi(returns, m+1, tol)
+}
+
+// apEnPhi - m uzunluklu her şablonun tol içinde eşleşme oranının logaritma ortalaması (öz eşleşme dahil)
+func apEnPhi(values []float64, m int, tol float64) float64 {
+	count := len(values) - m + 1
+	sum := 0.0
+	for i := 0; i < count; i++ {
+		matches := 0
+		for j := 0; j < count; j++ {
+			match := true
+			for k := 0; k < m; k++ {
+				if math.Abs(values[i+k]-values[j+k]) > tol {
+					match = false
+					break
+				}
+			}
+			if match {
+				matches++
+			}
+		}
+		sum += math.Log(float64(matches) / float64(count))
+	}
+	return sum / float64(count)
+}
+
+// Mean reversion skor ağırlıkları
+const (
+	mrBandWeight     = 0.4 // ±1σ bandı içindeki kapanış oranı
+	mrAutocorrWeight = 0.3 // lag-1 getiri otokorelasyonu (negatif iyi)
+	mrHurstWeight    = 0.3 // Hurst üssü (0.5 altı iyi)
+)
+
+// MeanReversionScore - son period kapanışın ne kadar yatay (grid'e uygun) seyrettiğini 0-100 arası puanlar.
+// Strateji dışında, ham fiyat dilimi ile varlıkları grid öncesi elemek için kullanılabilir.
+// Bileşenler: ortalamanın ±1σ içindeki kapanış oranı, lag-1 getiri otokorelasyonu ve Hurst üssü.
+// Veri yetersizse NaN döner; Hurst hesaplanamazsa o bileşen nötr (0.5) sayılır.
+func MeanReversionScore(close []float64, period int) float64 {
+	if period < 3 || len(close) < period {
+		return math.NaN()
+	}
+	window := close[len(close)-period:]
+	mean, std := meanStd(window)
+	inBand := 0
+	for _, v := range window {
+		if math.Abs(v-mean) <= std {
+			inBand++
+		}
+	}
+	bandScore := float64(inBand) / float64(len(window))
+
+	returns := make([]float64, 0, len(window)-1)
+	for i := 1; i < len(window); i++ {
+		if window[i-1] <= 0 {
+			return math.NaN()
+		}
+		returns = append(returns, window[i]/window[i-1]-1)
+	}
+	autocorrScore := (1 - lagOneAutocorrelation(returns)) / 2
+
+	hurstScore := 0.5
+	if hurst := hurstExponent(window); !math.IsNaN(hurst) {
+		hurstScore = clamp01(1 - hurst)
+	}
+	return 100 * (mrBandWeight*bandScore + mrAutocorrWeight*autocorrScore + mrHurstWeight*hurstScore)
+}
+
+// lagOneAutocorrelation - ardışık değerler arasındaki korelasyon, -1 ile 1 arası (varyans yoksa 0)
+func lagOneAutocorrelation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean, _ := meanStd(values)
+	var num, den float64
+	for i, v := range values {
+		den += (v - mean) * (v - mean)
+		if i > 0 {
+			num += (v - mean) * (values[i-1] - mean)
+		}
+	}
+	if den == 0 {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, num/den))
+}
@@ -0,0 +1,42 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApproximateEntropy(t *testing.T) {
+	smooth := priceSeries{amplitude: 2, period: 20}.closes(150)
+	low := computeApproximateEntropy(newSeries(smooth...), entropyEmbedding, entropyTolerance)
+	high := computeApproximateEntropy(newSeries(priceSeries{noise: 1, seed: 7}.closes(150)...), entropyEmbedding, entropyTolerance)
+	if math.IsNaN(low) || math.IsNaN(high) {
+		t.Fatalf("entropy NaN: smooth=%.4f noise=%.4f", low, high)
+	}
+	if low >= high {
+		t.Errorf("smooth entropy %.4f not below noise entropy %.4f", low, high)
+	}
+	if got := computeApproximateEntropy(newSeries(100, 100, 100, 100, 100), entropyEmbedding, entropyTolerance); got != 0 {
+		t.Errorf("flat series entropy = %.4f, want 0", got)
+	}
+	if got := computeApproximateEntropy(newSeries(100, 101), entropyEmbedding, entropyTolerance); !math.IsNaN(got) {
+		t.Errorf("short series entropy = %.4f, want NaN", got)
+	}
+}
+
+func TestMeanReversionScore(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		walk := MeanReversionScore(priceSeries{noise: 1, seed: seed}.closes(300), 200)
+		reverting := MeanReversionScore(priceSeries{noise: 1, reversion: 0.5, seed: seed}.closes(300), 200)
+		for _, score := range []float64{walk, reverting} {
+			if math.IsNaN(score) || score < 0 || score > 100 {
+				t.Fatalf("seed %d: score %.2f outside [0, 100]", seed, score)
+			}
+		}
+		if reverting <= walk {
+			t.Errorf("seed %d: mean-reverting score %.2f not above random walk %.2f", seed, reverting, walk)
+		}
+	}
+	if got := MeanReversionScore([]float64{100, 101}, 20); !math.IsNaN(got) {
+		t.Errorf("short input score = %.2f, want NaN", got)
+	}
+}
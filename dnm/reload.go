@@ -0,0 +1,124 @@
+package dnm
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/banbox/banbot/config"
+)
+
+// configDebounce - dosya değişikliğinden sonra okumadan önce beklenecek süre (yarım yazılmış dosya için)
+const configDebounce = 500 * time.Millisecond
+
+// liveConfig - watchConfigFile'ın en son okuduğu config değerleri.
+// pol.Def yalnızca varsayılan atadığından, çalışma anındaki değişiklikler bu yapı üzerinden yayınlanır.
+type liveConfig struct {
+	mu      sync.Mutex
+	version uint64
+	values  map[string]interface{}
+}
+
+// liveConfigs - *config.RunPolicyConfig -> *liveConfig
+var liveConfigs sync.Map
+
+func liveConfigFor(pol *config.RunPolicyConfig) *liveConfig {
+	val, _ := liveConfigs.LoadOrStore(pol, &liveConfig{})
+	return val.(*liveConfig)
+}
+
+// dropLiveConfig - strateji kapanırken pol'ün yayınlanan config kaydını siler
+func dropLiveConfig(pol *config.RunPolicyConfig) {
+	liveConfigs.Delete(pol)
+}
+
+func publishLiveConfig(pol *config.RunPolicyConfig, values map[string]interface{}) {
+	live := liveConfigFor(pol)
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	live.version++
+	live.values = values
+}
+
+// since - version'dan sonra yeni config yayınlandıysa değerleri ve güncel version'ı döndürür
+func (c *liveConfig) since(version uint64) (map[string]interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == version {
+		return nil, version, false
+	}
+	return c.values, c.version, true
+}
+
+// consume - tek seferlik bir parametreyi yayınlanan değerlerden siler (version değişmez)
+func (c *liveConfig) consume(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.values[name]; !ok {
+		return
+	}
+	values := make(map[string]interface{}, len(c.values))
+	for key, val := range c.values {
+		if key != name {
+			values[key] = val
+		}
+	}
+	c.values = values
+}
+
+// setLiveParam - değer varsa ve tipi uyuyorsa target'a yazar
+func setLiveParam[T any](values map[string]interface{}, name string, target *T) bool {
+	val, ok := values[name].(T)
+	if ok {
+		*target = val
+	}
+	return ok
+}
+
+// watchConfigFile - JSON config dosyasını interval aralıklarla yoklar. Değişiklik görüldüğünde
+// dosya configDebounce boyunca değişmezse yeniden okunur ve değerler pol için yayınlanır.
+// Dönen stop fonksiyonu goroutine'i sonlandırır.
+func watchConfigFile(path string, pol *config.RunPolicyConfig, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var lastMod time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastMod = info.ModTime()
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.ModTime().Equal(lastMod) {
+				continue
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(configDebounce):
+			}
+			// Yazma sürüyorsa bir sonraki turda tekrar dene
+			if again, err := os.Stat(path); err != nil || !again.ModTime().Equal(info.ModTime()) {
+				continue
+			}
+			lastMod = info.ModTime()
+			values, err := readGridConfig(path)
+			if err != nil {
+				log.Printf("grid config reload skipped: %v", err)
+				continue
+			}
+			publishLiveConfig(pol, values)
+			log.Printf("grid config reloaded from %s (%d params)", path, len(values))
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,78 @@
+package dnm
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/banbox/banbot/config"
+)
+
+func TestWatchConfigFilePublishesChanges(t *testing.T) {
+	path := writeGridConfig(t, `{"base_grid_count": 5, "base_spacing_pct": 0.5}`)
+	pol := &config.RunPolicyConfig{}
+	stop := watchConfigFile(path, pol, 20*time.Millisecond)
+	defer stop()
+
+	live := liveConfigFor(pol)
+	if _, _, changed := live.since(0); changed {
+		t.Fatal("config published before the file changed")
+	}
+
+	// Backtest ortasında yeni config yazılır; mtime ileri alınarak değişiklik garanti edilir
+	if err := os.WriteFile(path, []byte(`{"base_grid_count": 7, "base_spacing_pct": 0.8}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	next := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, next, next); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		values, version, changed := live.since(0)
+		if !changed {
+			time.Sleep(20 * time.Millisecond)
+			continue
+		}
+		count, spacing := 0, 0.0
+		if !setLiveParam(values, "base_grid_count", &count) || !setLiveParam(values, "base_spacing_pct", &spacing) {
+			t.Fatalf("reloaded values missing parameters: %v", values)
+		}
+		if count != 7 || spacing != 0.8 {
+			t.Errorf("reloaded base_grid_count=%d base_spacing_pct=%.2f, want 7 and 0.80", count, spacing)
+		}
+		if _, _, again := live.since(version); again {
+			t.Error("same version reported as changed twice")
+		}
+		return
+	}
+	t.Fatal("config change not published within 3s")
+}
+
+// stop sonrası dosya değişikliği yayınlanmamalı; dropLiveConfig kaydı silmeli
+func TestWatchConfigFileStopAndDrop(t *testing.T) {
+	path := writeGridConfig(t, `{"base_grid_count": 5}`)
+	pol := &config.RunPolicyConfig{}
+	stop := watchConfigFile(path, pol, 10*time.Millisecond)
+	stop()
+	stop() // ikinci çağrı panik yapmamalı
+
+	if err := os.WriteFile(path, []byte(`{"base_grid_count": 9}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	next := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, next, next); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(configDebounce + 100*time.Millisecond)
+	if _, ok := liveConfigs.Load(pol); ok {
+		t.Fatal("config published after stop")
+	}
+
+	publishLiveConfig(pol, map[string]interface{}{"base_grid_count": 9})
+	dropLiveConfig(pol)
+	if _, ok := liveConfigs.Load(pol); ok {
+		t.Error("live config entry kept after drop")
+	}
+}
@@ -0,0 +1,142 @@
+package dnm
+
+import (
+	"math"
+	"strings"
+)
+
+// Restriction - emir açmayı engelleyen filtreler (bitmask).
+// Her bar buy ve sell tarafı için ayrı maske hesaplanır; grid kurulumunu engelleyenler ayrı maskededir.
+type Restriction uint32
+
+const (
+	RestrictionCCIFilter Restriction = 1 << iota
+	RestrictionIchimoku
+	RestrictionDonchianBreakout
+	RestrictionSymmetry
+	RestrictionLowVolume
+	RestrictionSeasonality
+	RestrictionUnprofitable
+	RestrictionTrendingRegime
+	RestrictionDailyLoss
+	RestrictionHighEntropy
+	RestrictionDeltaFilter
+	RestrictionStochFilter
+	RestrictionSignalScore
+)
+
+var restrictionNames = []struct {
+	flag Restriction
+	name string
+}{
+	{RestrictionCCIFilter, "cci"},
+	{RestrictionIchimoku, "ichimoku"},
+	{RestrictionDonchianBreakout, "donchian_breakout"},
+	{RestrictionSymmetry, "symmetry"},
+	{RestrictionLowVolume, "low_volume"},
+	{RestrictionSeasonality, "seasonality"},
+	{RestrictionUnprofitable, "unprofitable"},
+	{RestrictionTrendingRegime, "trending_regime"},
+	{RestrictionDailyLoss, "daily_loss"},
+	{RestrictionHighEntropy, "high_entropy"},
+	{RestrictionDeltaFilter, "delta"},
+	{RestrictionStochFilter, "stoch"},
+	{RestrictionSignalScore, "signal_score"},
+}
+
+func (r Restriction) String() string {
+	if r == 0 {
+		return "none"
+	}
+	var names []string
+	for _, item := range restrictionNames {
+		if r&item.flag != 0 {
+			names = append(names, item.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// cciRestrictions - CCI aşırı alımda buy, aşırı satımda sell seviyelerini engeller
+func cciRestrictions(cci, overbought, oversold float64) (buy, sell Restriction) {
+	if cci > overbought {
+		buy |= RestrictionCCIFilter
+	}
+	if cci < oversold {
+		sell |= RestrictionCCIFilter
+	}
+	return buy, sell
+}
+
+// deltaRestrictions - kümülatif delta alış baskısı göstermiyorsa buy, satış baskısı göstermiyorsa sell seviyelerini engeller
+func deltaRestrictions(delta float64) (buy, sell Restriction) {
+	if !isDeltaConfirmed(delta, LevelBuy) {
+		buy |= RestrictionDeltaFilter
+	}
+	if !isDeltaConfirmed(delta, LevelSell) {
+		sell |= RestrictionDeltaFilter
+	}
+	return buy, sell
+}
+
+// stochRestrictions - buy yalnızca aşırı satımda %K'nın %D'yi yukarı kestiği barda, sell yalnızca
+// aşırı alımda aşağı kestiği barda serbesttir. Önceki bar değeri yoksa (NaN) kesişim sayılmaz.
+func stochRestrictions(k, d, prevK, prevD float64) (buy, sell Restriction) {
+	if !(k < 20 && k > d && prevK <= prevD) {
+		buy |= RestrictionStochFilter
+	}
+	if !(k > 80 && k < d && prevK >= prevD) {
+		sell |= RestrictionStochFilter
+	}
+	return buy, sell
+}
+
+// ichimokuRestrictions - fiyat bulutun altındaysa buy, üstündeyse sell seviyelerini engeller
+func ichimokuRestrictions(price, spanA, spanB float64) (buy, sell Restriction) {
+	if math.IsNaN(spanA) || math.IsNaN(spanB) {
+		return 0, 0
+	}
+	if price < math.Min(spanA, spanB) {
+		buy |= RestrictionIchimoku
+	}
+	if price > math.Max(spanA, spanB) {
+		sell |= RestrictionIchimoku
+	}
+	return buy, sell
+}
+
+// donchianRestriction - fiyat Donchian kanalının dışındaysa (kırılım) grid kurulumunu engeller
+func donchianRestriction(price, upper, lower float64) Restriction {
+	if math.IsNaN(upper) || math.IsNaN(lower) {
+		return 0
+	}
+	if price > upper || price < lower {
+		return RestrictionDonchianBreakout
+	}
+	return 0
+}
+
+// symmetryRestrictions - long/short dolar farkı sermayenin maxImbalancePct'ini aşarsa
+// fazla temsil edilen tarafı diğer taraf yetişene kadar durdurur
+func symmetryRestrictions(imbalance, capital, maxImbalancePct float64) (buy, sell Restriction) {
+	limit := capital * maxImbalancePct / 100
+	if imbalance > limit {
+		buy |= RestrictionSymmetry
+	}
+	if imbalance < -limit {
+		sell |= RestrictionSymmetry
+	}
+	return buy, sell
+}
+
+// volumeRestriction - hacim ortalamanın spikeFactor katını geçmedikçe iki tarafı da engeller.
+// Seviye Executed işaretlenmez, hacim geldiği ilk barda tetiklenebilir.
+func volumeRestriction(volume, volumeMA, spikeFactor float64) Restriction {
+	if math.IsNaN(volumeMA) || volumeMA <= 0 {
+		return 0
+	}
+	if volume > volumeMA*spikeFactor {
+		return 0
+	}
+	return RestrictionLowVolume
+}
@@ -0,0 +1,82 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCCIRestrictions(t *testing.T) {
+	tests := []struct {
+		name     string
+		cci      float64
+		wantBuy  Restriction
+		wantSell Restriction
+	}{
+		{name: "overbought blocks buys only", cci: 150, wantBuy: RestrictionCCIFilter},
+		{name: "oversold blocks sells only", cci: -150, wantSell: RestrictionCCIFilter},
+		{name: "neutral allows both", cci: 20},
+		{name: "threshold itself allowed", cci: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy, sell := cciRestrictions(tt.cci, 100, -100)
+			if buy != tt.wantBuy || sell != tt.wantSell {
+				t.Errorf("cciRestrictions(%.0f) = %s/%s, want %s/%s", tt.cci, buy, sell, tt.wantBuy, tt.wantSell)
+			}
+		})
+	}
+}
+
+// Fiyatı doğru seviyedeki bar düşük hacimde atlanır, sonraki yüksek hacimli barda tetiklenir
+func TestVolumeConfirmation(t *testing.T) {
+	level := GridLevel{Name: "B1", Type: LevelBuy, Price: 99, Active: true}
+	bars := []struct {
+		low, high, volume float64
+	}{
+		{low: 98.5, high: 99.5, volume: 120},
+		{low: 98.8, high: 99.6, volume: 200},
+	}
+	const volumeMA, spikeFactor = 100.0, 1.5
+	firedAt, lowVolumeSkips := -1, 0
+	for i, bar := range bars {
+		if level.Executed || !isLevelTriggered(level.Price, bar.low, bar.high, 0, 0) {
+			continue
+		}
+		if volumeRestriction(bar.volume, volumeMA, spikeFactor) == RestrictionLowVolume {
+			lowVolumeSkips++
+			continue
+		}
+		level.Executed = true
+		firedAt = i
+	}
+	if lowVolumeSkips != 1 || firedAt != 1 {
+		t.Errorf("skips=%d firedAt=%d, want 1 skip then fire on bar 1", lowVolumeSkips, firedAt)
+	}
+	if r := volumeRestriction(120, math.NaN(), spikeFactor); r != 0 {
+		t.Errorf("missing volume MA must not restrict, got %s", r)
+	}
+}
+
+func TestStochRestrictions(t *testing.T) {
+	tests := []struct {
+		name         string
+		k, d         float64
+		prevK, prevD float64
+		wantBuy      Restriction
+		wantSell     Restriction
+	}{
+		{name: "overbought blocks buy", k: 85, d: 80, prevK: 75, prevD: 78, wantBuy: RestrictionStochFilter, wantSell: RestrictionStochFilter},
+		{name: "oversold bullish cross allows buy", k: 15, d: 12, prevK: 10, prevD: 12, wantSell: RestrictionStochFilter},
+		{name: "overbought bearish cross allows sell", k: 85, d: 88, prevK: 90, prevD: 88, wantBuy: RestrictionStochFilter},
+		{name: "oversold without cross", k: 15, d: 12, prevK: 14, prevD: 12, wantBuy: RestrictionStochFilter, wantSell: RestrictionStochFilter},
+		{name: "missing previous bar", k: 15, d: 12, prevK: math.NaN(), prevD: math.NaN(), wantBuy: RestrictionStochFilter, wantSell: RestrictionStochFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy, sell := stochRestrictions(tt.k, tt.d, tt.prevK, tt.prevD)
+			if buy != tt.wantBuy || sell != tt.wantSell {
+				t.Errorf("stochRestrictions = %s/%s, want %s/%s", buy, sell, tt.wantBuy, tt.wantSell)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package dnm
+
+import (
+	"math"
+
+	"github.com/banbox/banbot/biz"
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+)
+
+// driftIntervals - uyarı için eşiğin art arda aşılması gereken durum aralığı sayısı
+const driftIntervals = 3
+
+// updateDriftMetrics - açık alış/satış seviye sayılarıyla drift oranını günceller.
+// Oran (ya da satış tarafı için 1 - oran) maxRatio'yu driftIntervals kez art arda aşarsa true döner;
+// uyarı oran eşiğin altına inene kadar tekrarlanmaz.
+func updateDriftMetrics(m *DriftMetrics, buys, sells int, maxRatio float64) bool {
+	m.BuyFillCount, m.SellFillCount = buys, sells
+	total := buys + sells
+	if total == 0 {
+		m.DriftRatio = 0
+		m.overIntervals = 0
+		m.DriftWarningFired = false
+		return false
+	}
+	m.DriftRatio = float64(buys) / float64(total)
+	if math.Max(m.DriftRatio, 1-m.DriftRatio) <= maxRatio {
+		m.overIntervals = 0
+		m.DriftWarningFired = false
+		return false
+	}
+	m.overIntervals++
+	if m.overIntervals < driftIntervals || m.DriftWarningFired {
+		return false
+	}
+	m.DriftWarningFired = true
+	return true
+}
+
+// computeLiquidationPrice - kaldıraçlı long pozisyonların yaklaşık tasfiye fiyatı:
+// ağırlıklı ortalama giriş * (1 - 1/leverage + mmRate). Pozisyon yoksa 0 döner.
+func computeLiquidationPrice(entryPrices []float64, sizes []float64, leverage, mmRate float64) float64 {
+	if leverage <= 0 || len(entryPrices) != len(sizes) {
+		return 0
+	}
+	var cost, amount float64
+	for i, price := range entryPrices {
+		cost += price * sizes[i]
+		amount += sizes[i]
+	}
+	if amount <= 0 {
+		return 0
+	}
+	return cost / amount * (1 - 1/leverage + mmRate)
+}
+
+// projectedLiquidation - dolmuş long emirlere fiyat price'tan amount eklenirse oluşacak tasfiye fiyatı
+func projectedLiquidation(orders []*core.Order, price, amount, leverage, mmRate float64) float64 {
+	entries := []float64{price}
+	sizes := []float64{amount}
+	for _, order := range orders {
+		if order.Status == core.OdStatusFull {
+			entries = append(entries, order.AvgPrice)
+			sizes = append(sizes, order.Amount)
+		}
+	}
+	return computeLiquidationPrice(entries, sizes, leverage, mmRate)
+}
+
+// checkDailyLoss - session başından beri equity kaybı maxLossPct'yi aştı mı? (maxLossPct <= 0 ise kapalı)
+func checkDailyLoss(currentEquity, sessionStartEquity, maxLossPct float64) bool {
+	if maxLossPct <= 0 || sessionStartEquity <= 0 {
+		return false
+	}
+	return currentEquity-sessionStartEquity < -maxLossPct/100*sessionStartEquity
+}
+
+// growCapitalBase - yeni grid döngüsünün boyutlandırma sermayesi: başlangıç sermayesine gerçekleşmiş
+// PnL'in reinvestPct'si eklenir. Sonuç 0 ile originalCapital * maxMultiplier arasında sınırlanır.
+func growCapitalBase(originalCapital, realizedPnl, reinvestPct, maxMultiplier float64) float64 {
+	grown := originalCapital + realizedPnl*reinvestPct/100
+	return math.Max(0, math.Min(grown, originalCapital*maxMultiplier))
+}
+
+// isGridUnstable - son window bar içindeki rebalance sayısı maxRebalances'ı aşıyor mu?
+// Sık rebalance, aralığın piyasa için bozuk olduğunu ve her seferinde zarar realize edildiğini gösterir.
+func isGridUnstable(rebalanceTimes []int, currentBar, window, maxRebalances int) bool {
+	count := 0
+	for _, bar := range rebalanceTimes {
+		if currentBar-bar < window {
+			count++
+		}
+	}
+	return count > maxRebalances
+}
+
+// checkPnLBounds - gerçekleşmiş PnL yüzdesi hedefe ya da zarar limitine ulaştı mı?
+// targetPct veya stopPct 0 ise ilgili kontrol kapalıdır.
+func checkPnLBounds(realized, initial, targetPct, stopPct float64) (hitTarget, hitStop bool) {
+	if initial <= 0 {
+		return false, false
+	}
+	pnlPct := realized / initial * 100
+	hitTarget = targetPct > 0 && pnlPct >= targetPct
+	hitStop = stopPct > 0 && pnlPct <= -stopPct
+	return hitTarget, hitStop
+}
+
+// runPreGridCheck - grid kurulmadan önce check hook'unu çalıştırır (nil ise geçer). Başarısız her kontrol
+// retries'ı artırır ve kurulumu sonraki bara erteler; maxRetries'a ulaşıldığında kurulum kalıcı olarak durur.
+func runPreGridCheck(s *strat.StratJob, check func(*strat.StratJob) error, retries, maxRetries int) (bool, int) {
+	if retries >= maxRetries {
+		return false, retries
+	}
+	if check == nil {
+		return true, retries
+	}
+	err := check(s)
+	if err == nil {
+		return true, retries
+	}
+	retries++
+	if retries >= maxRetries {
+		s.Infof("FATAL: grid pre-check failed %d times, grid will not be initialized: %v", retries, err)
+	} else {
+		s.Infof("Grid pre-check failed (%d/%d), retrying next bar: %v", retries, maxRetries, err)
+	}
+	return false, retries
+}
+
+// checkAutoRestart - durdurulan grid maxSessions yeni session geçtikten sonra yeniden açılabilir mi?
+// maxSessions 0 ise otomatik yeniden başlatma kapalıdır.
+func checkAutoRestart(s *strat.StratJob, haltedSessions, maxSessions int) bool {
+	if maxSessions <= 0 || haltedSessions < maxSessions {
+		return false
+	}
+	s.Infof("Grid auto-restart after %d sessions halted", haltedSessions)
+	return true
+}
+
+// applyFunding - dolmuş perpetual pozisyonlara funding uygular ve toplam maliyeti döndürür.
+// Pozitif oranda long'lar öder, short'lar alır; ödeme her emrin RealizedPnl'ine yansıtılır.
+func applyFunding(orders []*core.Order, fundingPct float64, isLong bool) float64 {
+	total := 0.0
+	for _, order := range orders {
+		if order.Status != core.OdStatusFull {
+			continue
+		}
+		cost := order.AvgPrice * order.Amount * fundingPct / 100
+		if !isLong {
+			cost = -cost
+		}
+		order.RealizedPnl -= cost
+		total += cost
+	}
+	return total
+}
+
+// computeExposureImbalance - instance'ın dolmuş long ve short pozisyonlarının dolar farkı (long - short)
+func computeExposureImbalance(s *strat.StratJob, instanceID string) float64 {
+	prefix := gridTagPrefix(instanceID)
+	return positionCost(GridLongOrders(s, prefix)) - positionCost(GridShortOrders(s, prefix))
+}
+
+// unrealizedPnL - dolmuş emirlerin price fiyatındaki açık PnL'i
+func unrealizedPnL(orders []*core.Order, price float64) float64 {
+	pnl := 0.0
+	for _, order := range orders {
+		if order.Status != core.OdStatusFull {
+			continue
+		}
+		diff := (price - order.AvgPrice) * order.Amount
+		if order.Short {
+			diff = -diff
+		}
+		pnl += diff
+	}
+	return pnl
+}
+
+// walletEquity - job hesabının cüzdanındaki toplam bakiye (yasal para biriminde, açık pozisyonlar dahil).
+// Cüzdan henüz yüklenmediyse ya da bakiye okunamadıysa fallback döner.
+func walletEquity(s *strat.StratJob, fallback float64) float64 {
+	wallets := biz.GetWallets(s.Account)
+	if wallets == nil {
+		return fallback
+	}
+	if total := wallets.TotalLegal(nil, true); total > 0 {
+		return total
+	}
+	return fallback
+}
+
+// positionCost - dolmuş emirlerin giriş maliyeti
+func positionCost(orders []*core.Order) float64 {
+	cost := 0.0
+	for _, order := range orders {
+		if order.Status == core.OdStatusFull {
+			cost += order.AvgPrice * order.Amount
+		}
+	}
+	return cost
+}
+
+// Delta hedge modları (delta_hedge_mode)
+const (
+	DeltaHedgeOff  = 0
+	DeltaHedgeSize = 1 // sonraki emir boyutları delta'yı hedefe çekecek şekilde ayarlanır
+)
+
+// Delta hedge ayarı: hedeften sapan her baz emir boyutu için %25, en fazla %75 boyut değişimi
+const (
+	deltaHedgeStep   = 0.25
+	deltaHedgeMaxAdj = 0.75
+)
+
+// portfolioDelta - instance'ın dolmuş long ve short pozisyonlarının miktar farkı (long - short)
+func portfolioDelta(s *strat.StratJob, instanceID string) float64 {
+	prefix := gridTagPrefix(instanceID)
+	delta := 0.0
+	for _, order := range GridLongOrders(s, prefix) {
+		if order.Status == core.OdStatusFull {
+			delta += order.Amount
+		}
+	}
+	for _, order := range GridShortOrders(s, prefix) {
+		if order.Status == core.OdStatusFull {
+			delta -= order.Amount
+		}
+	}
+	return delta
+}
+
+// computeDeltaAdjustment - long ve short emir boyutu çarpanları. Delta hedefin üstündeyse (fazla long)
+// alışlar küçülür, satışlar büyür; altındaysa tersi. Sapma baseSize cinsinden ölçülür.
+func computeDeltaAdjustment(currentDelta, targetDelta, baseSize float64) (longAdj, shortAdj float64) {
+	if baseSize <= 0 {
+		return 1, 1
+	}
+	adj := (currentDelta - targetDelta) / baseSize * deltaHedgeStep
+	adj = math.Max(-deltaHedgeMaxAdj, math.Min(adj, deltaHedgeMaxAdj))
+	return 1 - adj, 1 + adj
+}
+
+// autoBalanceThresholdPct - long ve short açık getirileri arasında yeniden dağıtımı tetikleyen fark (%)
+const autoBalanceThresholdPct = 3.0
+
+// computeRebalanceRatios - long ve short pozisyonların açık getirisine (%) göre yeni emir boyutu çarpanları.
+// Bir taraf diğerinden %3'ten fazla öndeyse o tarafın boyutu %20 azaltılır, zarardaki tarafınki %20 artırılır.
+func computeRebalanceRatios(longPnl, shortPnl float64) (longRatio, shortRatio float64) {
+	switch diff := longPnl - shortPnl; {
+	case diff > autoBalanceThresholdPct:
+		return 0.8, 1.2
+	case diff < -autoBalanceThresholdPct:
+		return 1.2, 0.8
+	}
+	return 1, 1
+}
@@ -0,0 +1,210 @@
+package dnm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/banbox/banbot/core"
+	"github.com/banbox/banbot/strat"
+)
+
+// Grid 1 Ocak 10:00'da durdurulur; saatlik barlarda her UTC gün değişimi bir session sayılır
+func TestAutoRestartSessionCounting(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).Unix()
+	tests := []struct {
+		name        string
+		maxSessions int
+		wantRestart int64 // yeniden başlatılan barın zamanı, 0 ise hiç
+	}{
+		{name: "restart on first session", maxSessions: 1, wantRestart: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "restart after two sessions", maxSessions: 2, wantRestart: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "disabled", maxSessions: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &strat.StratJob{}
+			halted, haltedSessions := true, 0
+			var restartedAt, lastBarTime int64
+			for barTime := start; barTime < start+4*86400; barTime += 3600 {
+				if halted && lastBarTime > 0 && isNewSession(lastBarTime, barTime) {
+					haltedSessions++
+					if checkAutoRestart(s, haltedSessions, tt.maxSessions) {
+						halted = false
+						restartedAt = barTime
+					}
+				}
+				lastBarTime = barTime
+			}
+			if restartedAt != tt.wantRestart {
+				t.Errorf("restarted at %s, want %s", time.Unix(restartedAt, 0).UTC(), time.Unix(tt.wantRestart, 0).UTC())
+			}
+		})
+	}
+}
+
+func TestPreGridCheckRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int // ilk kaç kontrol başarısız
+		maxRetries int
+		wantInitAt int // grid'in kurulduğu bar (0'dan), -1 ise hiç
+	}{
+		{name: "three failures then success", failures: 3, maxRetries: 5, wantInitAt: 3},
+		{name: "no failures", maxRetries: 5},
+		{name: "retries exhausted", failures: 5, maxRetries: 5, wantInitAt: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			check := func(*strat.StratJob) error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("exchange unreachable")
+				}
+				return nil
+			}
+			s := &strat.StratJob{}
+			retries, initAt := 0, -1
+			for bar := 0; bar < 10 && initAt < 0; bar++ {
+				var ok bool
+				ok, retries = runPreGridCheck(s, check, retries, tt.maxRetries)
+				if ok {
+					initAt = bar
+				}
+			}
+			if initAt != tt.wantInitAt {
+				t.Errorf("initialized at bar %d, want %d", initAt, tt.wantInitAt)
+			}
+			if want := min(tt.failures, tt.maxRetries); retries != want {
+				t.Errorf("retries = %d, want %d", retries, want)
+			}
+			if tt.wantInitAt < 0 && calls != tt.maxRetries {
+				t.Errorf("check called %d times after giving up, want %d", calls, tt.maxRetries)
+			}
+		})
+	}
+
+	if ok, _ := runPreGridCheck(&strat.StratJob{}, nil, 0, 5); !ok {
+		t.Error("nil hook must pass")
+	}
+}
+
+// Fiyat 30 bar boyunca rebalance sınırının iki yanında salınır; cooldown içinde tek rebalance olmalı
+func TestRebalanceDebounce(t *testing.T) {
+	const maxDeviationPct, cooldownBars = 2.0, 20
+	base, lastRebalance := 100.0, 0 // grid 0. barda kuruldu
+	var rebalances []int
+	for bar := 1; bar <= 30; bar++ {
+		price := 100.0
+		if bar%2 == 1 {
+			price = 103
+		}
+		deviation := math.Abs(price-base) / base * 100
+		if deviation > maxDeviationPct && rebalanceCooledDown(bar, lastRebalance, cooldownBars) {
+			base, lastRebalance = price, bar
+			rebalances = append(rebalances, bar)
+		}
+	}
+	if len(rebalances) != 1 || rebalances[0] != 21 {
+		t.Errorf("rebalanced at bars %v, want only bar 21", rebalances)
+	}
+}
+
+func TestComputeRebalanceRatios(t *testing.T) {
+	tests := []struct {
+		name              string
+		longPnl, shortPnl float64 // yüzde
+		wantLong          float64
+		wantShort         float64
+	}{
+		{name: "balanced", longPnl: 1, shortPnl: 1, wantLong: 1, wantShort: 1},
+		{name: "small imbalance", longPnl: 2, shortPnl: 0, wantLong: 1, wantShort: 1},
+		{name: "exactly threshold", longPnl: 3, shortPnl: 0, wantLong: 1, wantShort: 1},
+		{name: "longs ahead", longPnl: 4, shortPnl: -1, wantLong: 0.8, wantShort: 1.2},
+		{name: "shorts ahead", longPnl: -2, shortPnl: 2, wantLong: 1.2, wantShort: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			longRatio, shortRatio := computeRebalanceRatios(tt.longPnl, tt.shortPnl)
+			assertFloat(t, "longRatio", longRatio, tt.wantLong)
+			assertFloat(t, "shortRatio", shortRatio, tt.wantShort)
+		})
+	}
+}
+
+func TestCheckDailyLoss(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentEquity float64
+		maxLossPct    float64
+		want          bool
+	}{
+		{name: "6% loss halts", currentEquity: 9400, maxLossPct: 5, want: true},
+		{name: "4% loss continues", currentEquity: 9600, maxLossPct: 5},
+		{name: "exactly at limit continues", currentEquity: 9500, maxLossPct: 5},
+		{name: "profit continues", currentEquity: 10500, maxLossPct: 5},
+		{name: "disabled", currentEquity: 5000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkDailyLoss(tt.currentEquity, 10000, tt.maxLossPct); got != tt.want {
+				t.Errorf("checkDailyLoss = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrowCapitalBaseOverCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		cyclePnl  []float64 // döngü başına gerçekleşmiş PnL; totalRealizedPnl birikimlidir
+		wantBases []float64
+	}{
+		{name: "steady growth", cyclePnl: []float64{400, 400, 400, 400, 400}, wantBases: []float64{1200, 1400, 1600, 1800, 2000}},
+		{name: "capped at 3x", cyclePnl: []float64{1000, 1000, 1000, 1000, 1000}, wantBases: []float64{1500, 2000, 2500, 3000, 3000}},
+		{name: "losses shrink and floor at zero", cyclePnl: []float64{-1000, -1000, -1000, 2000, 500}, wantBases: []float64{500, 0, 0, 500, 750}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := 0.0
+			for cycle, pnl := range tt.cyclePnl {
+				total += pnl
+				assertFloat(t, fmt.Sprintf("cycle %d", cycle+1), growCapitalBase(1000, total, 50, 3), tt.wantBases[cycle])
+			}
+		})
+	}
+}
+
+// Aynı sembolde iki instance: birinin pozisyonları diğerinin exposure ve delta hesabına girmemeli
+func TestExposureIsPerInstance(t *testing.T) {
+	filled := func(instanceID, levelType string, amount float64) *core.Order {
+		return &core.Order{
+			Tag:      levelTag(instanceID, levelType, 1),
+			Short:    levelType == LevelSell,
+			Status:   core.OdStatusFull,
+			AvgPrice: 100,
+			Amount:   amount,
+		}
+	}
+	s := &strat.StratJob{
+		LongOrders:  []*core.Order{filled("A", LevelBuy, 2), filled("B", LevelBuy, 5)},
+		ShortOrders: []*core.Order{filled("A", LevelSell, 1)},
+	}
+	tests := []struct {
+		instanceID               string
+		wantImbalance, wantDelta float64
+		wantCost                 float64
+	}{
+		{instanceID: "A", wantImbalance: 100, wantDelta: 1, wantCost: 300},
+		{instanceID: "B", wantImbalance: 500, wantDelta: 5, wantCost: 500},
+		{instanceID: "C"},
+	}
+	for _, tt := range tests {
+		assertFloat(t, tt.instanceID+" imbalance", computeExposureImbalance(s, tt.instanceID), tt.wantImbalance)
+		assertFloat(t, tt.instanceID+" delta", portfolioDelta(s, tt.instanceID), tt.wantDelta)
+		assertFloat(t, tt.instanceID+" cost", positionCost(GridOpenOrders(s, gridTagPrefix(tt.instanceID))), tt.wantCost)
+	}
+}
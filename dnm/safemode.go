@@ -0,0 +1,60 @@
+package dnm
+
+// safeModeRecoveryPct - safe mode'dan çıkış için equity'nin ulaşması gereken en düşük zirve yüzdesi
+const safeModeRecoveryPct = 85.0
+
+// safeModeExitMarginPct - çıkış eşiğinin giriş eşiğinin en az bu kadar üstünde kalması; her barda aç/kapa olmasını önler
+const safeModeExitMarginPct = 5.0
+
+// GridParams - safe mode'da değiştirilen GridPro parametreleri
+type GridParams struct {
+	BaseGridCount       int
+	BaseSpacingPct      float64
+	MaxConcurrentTrades int
+
+	// Opsiyonel filtreler
+	EnableRegimeFilter       bool
+	EnableEntropyFilter      bool
+	EnableCCIFilter          bool
+	EnableDeltaFilter        bool
+	EnableStochFilter        bool
+	EnableIchimokuFilter     bool
+	EnableDCFilter           bool
+	EnableVolumeConfirmation bool
+	EnableSeasonalityFilter  bool
+	EnableSignalScore        bool
+}
+
+// applySafeMode - daha az ve daha seyrek seviye, yarı işlem limiti; opsiyonel filtreler kapatılır
+func applySafeMode(params *GridParams) {
+	params.BaseGridCount = max(params.BaseGridCount/2, 1)
+	params.BaseSpacingPct *= 2
+	params.MaxConcurrentTrades = max(params.MaxConcurrentTrades/2, 1)
+	params.EnableRegimeFilter = false
+	params.EnableEntropyFilter = false
+	params.EnableCCIFilter = false
+	params.EnableDeltaFilter = false
+	params.EnableStochFilter = false
+	params.EnableIchimokuFilter = false
+	params.EnableDCFilter = false
+	params.EnableVolumeConfirmation = false
+	params.EnableSeasonalityFilter = false
+	params.EnableSignalScore = false
+}
+
+// safeModeExitPct - safe mode'dan çıkış eşiği: %85 ile equityPct+%5'in büyüğü, en fazla %100
+func safeModeExitPct(equityPct float64) float64 {
+	return min(max(safeModeRecoveryPct, equityPct+safeModeExitMarginPct), 100)
+}
+
+// safeModeTransition - equity zirvenin equityPct'sinin altına düşünce safe mode'a girilir,
+// zirvenin safeModeExitPct'sine dönünce çıkılır. Dönen değer safe mode'un yeni durumudur.
+func safeModeTransition(active bool, equity, peakEquity, equityPct float64) bool {
+	if peakEquity <= 0 {
+		return active
+	}
+	if !active {
+		return equity < peakEquity*equityPct/100
+	}
+	return equity < peakEquity*safeModeExitPct(equityPct)/100
+}
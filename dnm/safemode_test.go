@@ -0,0 +1,72 @@
+package dnm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Equity 100'den 68'e düşer (zirvenin %70'i altı), sonra 86'ya toparlanır (zirvenin %85'i üstü)
+func TestSafeModeTransitions(t *testing.T) {
+	equities := []float64{100, 90, 75, 68, 72, 80, 84, 86, 90}
+	wantActive := []bool{false, false, false, true, true, true, true, false, false}
+	active, peak := false, 0.0
+	for i, equity := range equities {
+		peak = max(peak, equity)
+		active = safeModeTransition(active, equity, peak, 70)
+		if active != wantActive[i] {
+			t.Errorf("equity %.0f: safe mode = %v, want %v", equity, active, wantActive[i])
+		}
+	}
+}
+
+// equityPct %85'in üstündeyken çıkış eşiği girişin %5 üstündedir; giriş barının ardından hemen çıkılmaz
+func TestSafeModeHighEquityPct(t *testing.T) {
+	equities := []float64{100, 92, 89, 91, 94, 95, 96}
+	wantActive := []bool{false, false, true, true, true, false, false}
+	active, peak := false, 0.0
+	for i, equity := range equities {
+		peak = max(peak, equity)
+		active = safeModeTransition(active, equity, peak, 90)
+		if active != wantActive[i] {
+			t.Errorf("equity %.0f: safe mode = %v, want %v", equity, active, wantActive[i])
+		}
+	}
+}
+
+func TestSafeModeExitPct(t *testing.T) {
+	tests := []struct {
+		equityPct, want float64
+	}{
+		{50, 85},
+		{70, 85},
+		{80, 85},
+		{90, 95},
+		{95, 100},
+	}
+	for _, tt := range tests {
+		assertFloat(t, "safeModeExitPct", safeModeExitPct(tt.equityPct), tt.want)
+	}
+}
+
+func TestApplySafeMode(t *testing.T) {
+	params := GridParams{
+		BaseGridCount:       6,
+		BaseSpacingPct:      0.5,
+		MaxConcurrentTrades: 5,
+		EnableRegimeFilter:  true,
+		EnableCCIFilter:     true,
+		EnableStochFilter:   true,
+		EnableSignalScore:   true,
+	}
+	applySafeMode(&params)
+	want := GridParams{BaseGridCount: 3, BaseSpacingPct: 1.0, MaxConcurrentTrades: 2}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("applySafeMode = %+v, want %+v", params, want)
+	}
+
+	small := GridParams{BaseGridCount: 1, MaxConcurrentTrades: 1}
+	applySafeMode(&small)
+	if small.BaseGridCount != 1 || small.MaxConcurrentTrades != 1 {
+		t.Errorf("counts must stay at least 1, got %+v", small)
+	}
+}
@@ -0,0 +1,42 @@
+package dnm
+
+import (
+	"math"
+	"time"
+)
+
+// seasonalityMinHistory - filtre devreye girmeden önce gereken geçmiş (4 hafta)
+const seasonalityMinHistory = 28 * 86400
+
+// updateSeasonalityGrid - bar getirisini UTC gün ve saat hücresinin ortalamasına ekler
+func updateSeasonalityGrid(grid *[7][24]float64, counts *[7][24]int, barTime int64, barReturn float64) {
+	if math.IsNaN(barReturn) {
+		return
+	}
+	t := time.Unix(barTime, 0).UTC()
+	day, hour := int(t.Weekday()), t.Hour()
+	counts[day][hour]++
+	grid[day][hour] += (barReturn - grid[day][hour]) / float64(counts[day][hour])
+}
+
+// seasonalityThreshold - dolu hücre ortalamalarının standart sapmasının negatifi.
+// Ortalama getirisi bunun altında kalan saatler "kötü" sayılır.
+func seasonalityThreshold(grid [7][24]float64, counts [7][24]int) float64 {
+	var values []float64
+	for day := range grid {
+		for hour := range grid[day] {
+			if counts[day][hour] > 0 {
+				values = append(values, grid[day][hour])
+			}
+		}
+	}
+	_, std := meanStd(values)
+	return -std
+}
+
+// isBadSeasonality - barın gün/saat hücresinin ortalama getirisi threshold'un altında mı?
+func isBadSeasonality(grid [7][24]float64, barTime int64, threshold float64) bool {
+	t := time.Unix(barTime, 0).UTC()
+	avg := grid[int(t.Weekday())][t.Hour()]
+	return avg < 0 && avg < threshold
+}
@@ -0,0 +1,60 @@
+package dnm
+
+import (
+	"math"
+	"math/rand"
+)
+
+// priceSeries - testlerde kullanılan sentetik kapanış serisi: 100 etrafında sinüs dalgası, doğrusal trend
+// ve rastgele yürüyüş bileşenlerinin toplamı. Sıfır alan o bileşeni kapatır.
+type priceSeries struct {
+	amplitude float64 // sinüs genliği
+	period    float64 // sinüs periyodu (bar)
+	trend     float64 // bar başına fiyat değişimi
+	noise     float64 // rastgele yürüyüş adımının standart sapması
+	reversion float64 // rastgele bileşenin her barda 0'a dönen oranı (0 = saf rastgele yürüyüş)
+	seed      int64
+}
+
+// closes - serinin ilk n kapanışı; aynı seed her çağrıda aynı seriyi üretir
+func (p priceSeries) closes(n int) []float64 {
+	rng := rand.New(rand.NewSource(p.seed))
+	res := make([]float64, n)
+	walk := 0.0
+	for i := range res {
+		if p.noise > 0 {
+			walk += -p.reversion*walk + rng.NormFloat64()*p.noise
+		}
+		price := 100 + walk + p.trend*float64(i)
+		if p.period > 0 {
+			price += p.amplitude * math.Sin(2*math.Pi*float64(i)/p.period)
+		}
+		res[i] = price
+	}
+	return res
+}
+
+// wilderCloses - Wilder'ın RSI örneğindeki kapanışlar, StochRSI için gereken uzunluğa tekrarlanır
+func wilderCloses(n int) []float64 {
+	sample := []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+		46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+		43.42, 42.66, 43.13,
+	}
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = sample[i%len(sample)]
+	}
+	return res
+}
+
+// firstTrue - fn'in ilk true döndüğü index, hiç dönmezse -1
+func firstTrue(n int, fn func(i int) bool) int {
+	for i := 0; i < n; i++ {
+		if fn(i) {
+			return i
+		}
+	}
+	return -1
+}
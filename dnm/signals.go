@@ -0,0 +1,67 @@
+package dnm
+
+import "math"
+
+// Sinyal skoru ölçekleri: bu değerlerde ilgili skor 0'a iner
+const (
+	signalCCIScale   = 200.0 // |CCI|
+	signalTrendScale = 5.0   // fiyatın trend çizgisine uzaklığı (%)
+)
+
+// GridSignal - 0-1 arası alt sinyal; 1 grid için uygun (yatay) piyasa demektir
+type GridSignal struct {
+	Name   string
+	Score  float64
+	Weight float64
+}
+
+// aggregateSignals - skorların ağırlıklı ortalaması. NaN skorlu ya da ağırlıksız sinyaller atlanır,
+// hiç geçerli sinyal yoksa 1 döner (kapı açık).
+func aggregateSignals(signals []GridSignal) float64 {
+	var sum, weights float64
+	for _, sig := range signals {
+		if math.IsNaN(sig.Score) || sig.Weight <= 0 {
+			continue
+		}
+		sum += clamp01(sig.Score) * sig.Weight
+		weights += sig.Weight
+	}
+	if weights == 0 {
+		return 1
+	}
+	return sum / weights
+}
+
+// defaultGridSignals - RSI, CCI, Stochastic %K, trend gücü (%) ve piyasa rejiminden eşit ağırlıklı sinyaller.
+// Osilatörler ortadayken, trend zayıfken ve rejim yataydayken skor yükselir.
+func defaultGridSignals(rsi, cci, stochK, trendStrength float64, regime MarketRegime) []GridSignal {
+	regimeScore := 1.0
+	switch regime {
+	case RegimeVolatile:
+		regimeScore = 0.5
+	case RegimeTrending:
+		regimeScore = 0
+	}
+	return []GridSignal{
+		{Name: "rsi", Score: 1 - math.Abs(rsi-50)/50, Weight: 1},
+		{Name: "cci", Score: 1 - math.Abs(cci)/signalCCIScale, Weight: 1},
+		{Name: "stoch", Score: 1 - math.Abs(stochK-50)/50, Weight: 1},
+		{Name: "trend", Score: 1 - math.Abs(trendStrength)/signalTrendScale, Weight: 1},
+		{Name: "regime", Score: regimeScore, Weight: 1},
+	}
+}
+
+// scoreRestrictions - skor eşiği aşmayan tarafı engeller
+func scoreRestrictions(score, minBuy, minSell float64) (buy, sell Restriction) {
+	if score <= minBuy {
+		buy |= RestrictionSignalScore
+	}
+	if score <= minSell {
+		sell |= RestrictionSignalScore
+	}
+	return buy, sell
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
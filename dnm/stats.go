@@ -0,0 +1,254 @@
+package dnm
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// GridStats - grid genelindeki sayaçlar ve metrikler
+type GridStats struct {
+	DeactivatedLevels int     `json:"deactivated_levels"`
+	FeeProtectedSkips int     `json:"fee_protected_skips"` // komisyonu karşılamadığı için ertelenen take-profit'ler
+	Sharpe            float64 `json:"sharpe"`
+	Sortino           float64 `json:"sortino"`
+	FundingCostTotal  float64 `json:"funding_cost_total"` // perpetual funding ödemeleri (pozitif = maliyet)
+	MergeCount        int     `json:"merge_count"`
+	SplitLevels       int     `json:"split_levels"`
+	AgedExits         int     `json:"aged_exits"`        // max_hold_bars aşıldığı için kapatılan pozisyonlar
+	TotalFills        int     `json:"total_fills"`       // tüm seviyelerde, tekrarlar dahil açılan grid emirleri
+	BackfillTrades    int     `json:"backfill_trades"`   // backfill_bars simülasyonu, gerçek emir değil
+	BackfillPnl       float64 `json:"backfill_pnl"`      // backfill_bars simülasyonu (açık pozisyonlar dahil), gerçek PnL değil
+	MaxConcurrentDD   float64 `json:"max_concurrent_dd"` // başlangıç sermayesinin en düşük equity'ye uzaklığı
+	RARoC             float64 `json:"raroc"`
+	ATRRatio          float64 `json:"atr_ratio"`           // ATR / fiyat
+	BBWidth           float64 `json:"bb_width"`            // (üst bant - alt bant) / üst bant
+	AvgBarDurationNs  int64   `json:"avg_bar_duration_ns"` // enable_profiling açıksa OnBar ortalama süresi
+	CurrentRegime     string  `json:"current_regime"`      // enable_regime_filter açıksa
+	CurrentEntropy    float64 `json:"current_entropy"`     // enable_entropy_filter açıksa getirilerin ApEn'i
+	TradeScore        float64 `json:"trade_score"`         // enable_signal_score açıksa alt sinyallerin ağırlıklı ortalaması
+
+	LevelClusters []LevelCluster  `json:"level_clusters"` // ATR yarıçapında kümelenen seviyeler
+	Drift         DriftMetrics    `json:"drift"`
+	Skips         GridSkipCounter `json:"skips"`
+	HeatMap       *PriceBinMap    `json:"-"`
+}
+
+// DriftMetrics - emri açık seviyelerin alış/satış dağılımı.
+// Dolumlar tek tarafta birikirse grid fiilen kaldıraçlı tek yönlü pozisyona dönüşür.
+type DriftMetrics struct {
+	BuyFillCount      int     `json:"buy_fill_count"`
+	SellFillCount     int     `json:"sell_fill_count"`
+	DriftRatio        float64 `json:"drift_ratio"` // alış / toplam
+	DriftWarningFired bool    `json:"drift_warning_fired"`
+
+	overIntervals int // eşiğin aşıldığı ardışık durum aralığı sayısı
+}
+
+// GridSkipCounter - emir açılmayan barların nedene göre sayısı.
+// Warmup'ın yetersiz mi, göstergelerin NaN mı ürettiğini ayırt etmek için kullanılır.
+type GridSkipCounter struct {
+	TooFewBars    int `json:"too_few_bars"`
+	NaNIndicators int `json:"nan_indicators"`
+	GridNotInit   int `json:"grid_not_init"`
+	CannotTrade   int `json:"cannot_trade"`
+	NoLevels      int `json:"no_levels"`
+	LowVolume     int `json:"low_volume"`
+}
+
+// jobStats - strateji dışından metriklere erişim için StratJob -> *GridStats.
+// Job durduğunda unregisterGridStats ile silinir; aksi halde biten job'lar bellekte kalır.
+var jobStats sync.Map
+
+func registerGridStats(s *strat.StratJob, stats *GridStats) {
+	jobStats.Store(s, stats)
+}
+
+func unregisterGridStats(s *strat.StratJob) {
+	jobStats.Delete(s)
+}
+
+func loadGridStats(s *strat.StratJob) (*GridStats, bool) {
+	val, ok := jobStats.Load(s)
+	if !ok {
+		return nil, false
+	}
+	return val.(*GridStats), true
+}
+
+// GetSharpe - job için son hesaplanan Sharpe oranı
+func GetSharpe(s *strat.StratJob) float64 {
+	if stats, ok := loadGridStats(s); ok {
+		return stats.Sharpe
+	}
+	return 0
+}
+
+// GetRARoC - job için son hesaplanan risk-adjusted return on capital
+func GetRARoC(s *strat.StratJob) float64 {
+	if stats, ok := loadGridStats(s); ok {
+		return stats.RARoC
+	}
+	return 0
+}
+
+// GetVolatilityMetrics - job için son barın normalize ATR'si ve Bollinger bant genişliği
+func GetVolatilityMetrics(s *strat.StratJob) (atrRatio, bbWidth float64) {
+	if stats, ok := loadGridStats(s); ok {
+		return stats.ATRRatio, stats.BBWidth
+	}
+	return 0, 0
+}
+
+// bollingerWidth - (üst bant - alt bant) / üst bant; bantlar henüz hesaplanmadıysa false
+func bollingerWidth(upper, lower float64) (float64, bool) {
+	if math.IsNaN(upper) || math.IsNaN(lower) || upper <= 0 {
+		return 0, false
+	}
+	return (upper - lower) / upper, true
+}
+
+// computeRARoC - Risk-Adjusted Return on Capital = gerçekleşmiş PnL / en büyük eşzamanlı drawdown.
+// Drawdown, grid başlangıcından beri görülen en düşük equity'nin (gerçekleşmiş + açık PnL)
+// başlangıç sermayesine uzaklığıdır. Henüz drawdown yoksa 0 döner.
+func computeRARoC(pnl, maxConcurrentDD float64) float64 {
+	if maxConcurrentDD <= 0 {
+		return 0
+	}
+	return pnl / maxConcurrentDD
+}
+
+// GetSkipCounters - job için emir açılmayan bar sayaçları
+func GetSkipCounters(s *strat.StratJob) GridSkipCounter {
+	if stats, ok := loadGridStats(s); ok {
+		return stats.Skips
+	}
+	return GridSkipCounter{}
+}
+
+// GetHeatMap - job için fiyat bin haritası (kapalıysa nil)
+func GetHeatMap(s *strat.StratJob) *PriceBinMap {
+	if stats, ok := loadGridStats(s); ok {
+		return stats.HeatMap
+	}
+	return nil
+}
+
+// PerfTracker - OnBar çalışma süresinin ortalaması
+type PerfTracker struct {
+	totalNs int64
+	count   int64
+}
+
+// Record - bir ölçüm ekler
+func (p *PerfTracker) Record(dur time.Duration) {
+	p.totalNs += dur.Nanoseconds()
+	p.count++
+}
+
+// Average - ölçümlerin ortalaması, ölçüm yoksa 0
+func (p *PerfTracker) Average() time.Duration {
+	if p.count == 0 {
+		return 0
+	}
+	return time.Duration(p.totalNs / p.count)
+}
+
+// Count - ölçüm sayısı
+func (p *PerfTracker) Count() int64 {
+	return p.count
+}
+
+// RollingReturns - kapanan işlemlerin son maxLen getirisini tutar
+type RollingReturns struct {
+	returns []float64
+	maxLen  int
+}
+
+func NewRollingReturns(maxLen int) *RollingReturns {
+	if maxLen < 2 {
+		maxLen = 2
+	}
+	return &RollingReturns{maxLen: maxLen}
+}
+
+// Add - yeni getiri ekler, pencere dolduysa en eskisini atar
+func (r *RollingReturns) Add(ret float64) {
+	r.returns = append(r.returns, ret)
+	if len(r.returns) > r.maxLen {
+		r.returns = r.returns[len(r.returns)-r.maxLen:]
+	}
+}
+
+// Len - penceredeki getiri sayısı
+func (r *RollingReturns) Len() int {
+	return len(r.returns)
+}
+
+// Sharpe - yıllıklandırılmış Sharpe; faktör sqrt(365 * günlük bar sayısı)
+func (r *RollingReturns) Sharpe(barSecs int64) float64 {
+	mean, std := meanStd(r.returns)
+	if std == 0 {
+		return 0
+	}
+	return mean / std * annualizationFactor(barSecs)
+}
+
+// Sortino - Sharpe ile aynı, ancak sadece negatif getirilerin sapması kullanılır
+func (r *RollingReturns) Sortino(barSecs int64) float64 {
+	if len(r.returns) < 2 {
+		return 0
+	}
+	mean, _ := meanStd(r.returns)
+	downside := 0.0
+	for _, ret := range r.returns {
+		if ret < 0 {
+			downside += ret * ret
+		}
+	}
+	downDev := math.Sqrt(downside / float64(len(r.returns)))
+	if downDev == 0 {
+		return 0
+	}
+	return mean / downDev * annualizationFactor(barSecs)
+}
+
+func annualizationFactor(barSecs int64) float64 {
+	if barSecs <= 0 {
+		return 1
+	}
+	barsPerDay := 86400 / float64(barSecs)
+	return math.Sqrt(365 * barsPerDay)
+}
+
+// meanStd - örneklem ortalaması ve standart sapması
+func meanStd(values []float64) (mean, std float64) {
+	n := len(values)
+	if n < 2 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(n-1))
+}
+
+// selectBestGridMode - günlük volatiliteye (%) göre en uygun grid modu.
+// < %1 sabit aralık, üzeri ATR bazlı. Market Profile modu GridPro'da olmadığından
+// > %3 rejimde de ATR bazlı mod seçilir.
+func selectBestGridMode(currentMode string, regimeVolatility float64) string {
+	// EvenOdd ve ATR Band seviye düzenini belirler, volatiliteye göre değiştirilmez
+	if currentMode == GridModeEvenOdd || currentMode == GridModeATRBand || math.IsNaN(regimeVolatility) || regimeVolatility <= 0 {
+		return currentMode
+	}
+	if regimeVolatility < 1 {
+		return GridModeFixed
+	}
+	return GridModeATR
+}
@@ -0,0 +1,49 @@
+package dnm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/banbox/banbot/strat"
+)
+
+func TestBollingerWidth(t *testing.T) {
+	closes := []float64{
+		100, 101, 102, 101.5, 100.5, 99.5, 99, 100, 101, 102.5,
+		103, 102, 101, 100, 99.5, 100.5, 101.5, 102, 101, 100,
+	}
+	mean, std := meanStd(closes)
+	upper, lower := mean+2*std, mean-2*std
+	tests := []struct {
+		name         string
+		upper, lower float64
+		want         float64
+		wantOK       bool
+	}{
+		{name: "20-bar bands", upper: upper, lower: lower, want: 4 * std / (mean + 2*std), wantOK: true},
+		{name: "flat bands", upper: 100, lower: 100, wantOK: true},
+		{name: "NaN band", upper: math.NaN(), lower: 99},
+		{name: "zero upper", upper: 0, lower: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bollingerWidth(tt.upper, tt.lower)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			assertFloat(t, "width", got, tt.want)
+		})
+	}
+}
+
+func TestGetVolatilityMetrics(t *testing.T) {
+	s := &strat.StratJob{}
+	if atrRatio, bbWidth := GetVolatilityMetrics(s); atrRatio != 0 || bbWidth != 0 {
+		t.Errorf("unregistered job returned %.4f, %.4f", atrRatio, bbWidth)
+	}
+	registerGridStats(s, &GridStats{ATRRatio: 0.012, BBWidth: 0.05})
+	defer unregisterGridStats(s)
+	atrRatio, bbWidth := GetVolatilityMetrics(s)
+	assertFloat(t, "atrRatio", atrRatio, 0.012)
+	assertFloat(t, "bbWidth", bbWidth, 0.05)
+}
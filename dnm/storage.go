@@ -0,0 +1,182 @@
+package dnm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrGridStateNotFound - key için kayıtlı grid durumu yok
+var ErrGridStateNotFound = errors.New("grid state not found")
+
+// StateVersion - GridSnapshot kayıt formatının sürümü
+type StateVersion int
+
+const (
+	StateVersionLegacy    StateVersion = iota // version alanı olmayan ilk format
+	StateVersionLevelMeta                     // seviye boyut çarpanı, giriş barı ve ratchet TP eklendi
+
+	CurrentStateVersion = StateVersionLevelMeta
+)
+
+// GridSnapshot - yeniden başlatmada grid'i kaldığı yerden sürdürmek için gereken durum
+type GridSnapshot struct {
+	Version     StateVersion         `json:"version"`
+	BasePrice   float64              `json:"base_price"`
+	Initialized bool                 `json:"initialized"`
+	Halted      bool                 `json:"halted"`
+	RealizedPnl float64              `json:"realized_pnl"`
+	Levels      map[string]GridLevel `json:"levels"`
+	BarTime     int64                `json:"bar_time"`
+}
+
+// GridStorage - grid durumunun saklandığı yer (dosya, SQLite, Redis vb.)
+type GridStorage interface {
+	Save(key string, state GridSnapshot) error
+	Load(key string) (GridSnapshot, error) // kayıt yoksa ErrGridStateNotFound
+	Delete(key string) error
+}
+
+// InMemoryGridStorage - süreç içinde tutulan depolama (test ve backtest için)
+type InMemoryGridStorage struct {
+	mu     sync.Mutex
+	states map[string]GridSnapshot
+}
+
+func NewInMemoryGridStorage() *InMemoryGridStorage {
+	return &InMemoryGridStorage{states: make(map[string]GridSnapshot)}
+}
+
+func (m *InMemoryGridStorage) Save(key string, state GridSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[key] = cloneSnapshot(state)
+	return nil
+}
+
+func (m *InMemoryGridStorage) Load(key string) (GridSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[key]
+	if !ok {
+		return GridSnapshot{}, ErrGridStateNotFound
+	}
+	return cloneSnapshot(state), nil
+}
+
+func (m *InMemoryGridStorage) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, key)
+	return nil
+}
+
+// migrateSnapshot - eski sürümde kaydedilmiş durumu güncel formata getirir. Eski kayıtlarda olmayan
+// alanlar sıfır değerle gelir; sıfır olamayacak alanlara varsayılan yazılır. EntryBarIndex 0 kalır,
+// yani max_hold_bars süresi yeniden başlatmadaki ilk bardan sayılır.
+func migrateSnapshot(old GridSnapshot) GridSnapshot {
+	if old.Version >= CurrentStateVersion {
+		return old
+	}
+	state := cloneSnapshot(old)
+	for name, level := range state.Levels {
+		if level.SizeMultiplier <= 0 {
+			level.SizeMultiplier = 1.0
+		}
+		state.Levels[name] = level
+	}
+	state.Version = CurrentStateVersion
+	return state
+}
+
+// cloneSnapshot - seviye map'i paylaşılmasın diye kopyalar
+func cloneSnapshot(state GridSnapshot) GridSnapshot {
+	levels := make(map[string]GridLevel, len(state.Levels))
+	for name, level := range state.Levels {
+		levels[name] = level
+	}
+	state.Levels = levels
+	return state
+}
+
+// FileGridStorage - her key için Dir altında bir JSON dosyası
+type FileGridStorage struct {
+	Dir string
+}
+
+func NewFileGridStorage(dir string) *FileGridStorage {
+	return &FileGridStorage{Dir: dir}
+}
+
+// path - key'deki ayraçlar dosya adında kullanılamadığı için "_" ile değiştirilir
+func (f *FileGridStorage) path(key string) string {
+	name := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(key)
+	return filepath.Join(f.Dir, name+".json")
+}
+
+func (f *FileGridStorage) Save(key string, state GridSnapshot) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return fmt.Errorf("create grid state dir: %w", err)
+	}
+	return writeFileAtomic(f.path(key), data)
+}
+
+func (f *FileGridStorage) Load(key string) (GridSnapshot, error) {
+	var state GridSnapshot
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, ErrGridStateNotFound
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("parse grid state %s: %w", key, err)
+	}
+	return state, nil
+}
+
+func (f *FileGridStorage) Delete(key string) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// selectGridStorage - persist_state kapalıysa nil; state_dir verilmişse dosya, değilse cfg.Storage,
+// o da nil ise instance'a özel InMemoryGridStorage
+func selectGridStorage(cfg GridConfig, persistState bool, stateDir string) GridStorage {
+	if !persistState {
+		return nil
+	}
+	if stateDir != "" {
+		return NewFileGridStorage(stateDir)
+	}
+	if cfg.Storage != nil {
+		return cfg.Storage
+	}
+	return NewInMemoryGridStorage()
+}
+
+// writeFileAtomic - data'yı önce path.tmp'ye yazıp path'e taşır; okuyan taraf yarım yazılmış dosya görmez
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// gridStateKey - sembol, zaman dilimi ve instance başına durum key'i
+func gridStateKey(symbol, timeFrame, instanceID string) string {
+	return fmt.Sprintf("grid_state:%s:%s:%s", symbol, timeFrame, instanceID)
+}
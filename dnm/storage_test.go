@@ -0,0 +1,137 @@
+package dnm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// tenLevelSnapshot - her iki tarafta 5'er seviyeli, bir kısmı dolmuş grid durumu
+func tenLevelSnapshot(t *testing.T) GridSnapshot {
+	t.Helper()
+	levels := newGridLevelMap()
+	updateGridLevels(levels, 100, 1, 5, 0, 0, 0, nil)
+	snap := levels.Snapshot()
+	for _, name := range []string{"B1", "B2", "S1"} {
+		level := snap[name]
+		level.Executed = true
+		level.Wins, level.Losses = 2, 1
+		level.EntryBarIndex = 42
+		snap[name] = level
+	}
+	if len(snap) != 10 {
+		t.Fatalf("snapshot has %d levels, want 10", len(snap))
+	}
+	return GridSnapshot{
+		Version:     CurrentStateVersion,
+		BasePrice:   100,
+		Initialized: true,
+		RealizedPnl: 12.5,
+		Levels:      snap,
+		BarTime:     1700000000,
+	}
+}
+
+func TestGridStorageRoundTrip(t *testing.T) {
+	storages := []struct {
+		name    string
+		storage GridStorage
+	}{
+		{name: "memory", storage: NewInMemoryGridStorage()},
+		{name: "file", storage: NewFileGridStorage(t.TempDir())},
+	}
+	for _, tt := range storages {
+		t.Run(tt.name, func(t *testing.T) {
+			key := gridStateKey("BTC/USDT:USDT", "1h", "0")
+			if _, err := tt.storage.Load(key); !errors.Is(err, ErrGridStateNotFound) {
+				t.Fatalf("Load before Save: err = %v, want ErrGridStateNotFound", err)
+			}
+			want := tenLevelSnapshot(t)
+			if err := tt.storage.Save(key, want); err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.storage.Load(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+			}
+
+			// Kaydedilen durum çağıranın map'iyle paylaşılmamalı
+			level := want.Levels["B1"]
+			level.Price = 1
+			want.Levels["B1"] = level
+			if again, _ := tt.storage.Load(key); again.Levels["B1"].Price == 1 {
+				t.Error("stored snapshot shares the caller's level map")
+			}
+
+			if err := tt.storage.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tt.storage.Load(key); !errors.Is(err, ErrGridStateNotFound) {
+				t.Errorf("Load after Delete: err = %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateSnapshot(t *testing.T) {
+	legacy := GridSnapshot{
+		BasePrice:   100,
+		Initialized: true,
+		Levels: map[string]GridLevel{
+			"B1": {Name: "B1", Type: LevelBuy, Price: 99, Executed: true},
+			"S1": {Name: "S1", Type: LevelSell, Price: 101, SizeMultiplier: 1.5},
+		},
+	}
+	got := migrateSnapshot(legacy)
+	if got.Version != CurrentStateVersion {
+		t.Errorf("Version = %d, want %d", got.Version, CurrentStateVersion)
+	}
+	tests := []struct {
+		name     string
+		wantSize float64
+	}{
+		{name: "B1", wantSize: 1}, // eski kayıtta yok, varsayılan
+		{name: "S1", wantSize: 1.5},
+	}
+	for _, tt := range tests {
+		level := got.Levels[tt.name]
+		assertFloat(t, tt.name+".SizeMultiplier", level.SizeMultiplier, tt.wantSize)
+		if level.EntryBarIndex != 0 || level.TakeProfit != 0 {
+			t.Errorf("%s: EntryBarIndex=%d TakeProfit=%.2f, want zero defaults", tt.name, level.EntryBarIndex, level.TakeProfit)
+		}
+	}
+	if !got.Levels["B1"].Executed || got.BasePrice != 100 || !got.Initialized {
+		t.Error("migration lost existing state")
+	}
+	if legacy.Levels["B1"].SizeMultiplier != 0 {
+		t.Error("migration modified the input snapshot")
+	}
+
+	current := tenLevelSnapshot(t)
+	if migrated := migrateSnapshot(current); !reflect.DeepEqual(migrated, current) {
+		t.Error("current-version snapshot changed by migration")
+	}
+}
+
+func TestSelectGridStorage(t *testing.T) {
+	custom := NewInMemoryGridStorage()
+	dir := t.TempDir()
+	if got := selectGridStorage(GridConfig{Storage: custom}, false, dir); got != nil {
+		t.Errorf("persist_state off: got %T, want nil", got)
+	}
+	if got, ok := selectGridStorage(GridConfig{Storage: custom}, true, dir).(*FileGridStorage); !ok || got.Dir != dir {
+		t.Errorf("state_dir set: got %v, want file storage in %s", got, dir)
+	}
+	if got := selectGridStorage(GridConfig{Storage: custom}, true, ""); got != custom {
+		t.Errorf("GridConfig.Storage not used, got %T", got)
+	}
+	// Varsayılan depolama instance'a özeldir; iki instance aynı nesneyi paylaşmaz
+	a, aok := selectGridStorage(GridConfig{}, true, "").(*InMemoryGridStorage)
+	b, bok := selectGridStorage(GridConfig{}, true, "").(*InMemoryGridStorage)
+	if !aok || !bok || a == b {
+		t.Errorf("default storage = %p, %p, want two distinct in-memory storages", a, b)
+	}
+}
@@ -0,0 +1,81 @@
+package dnm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/banbox/banbot/strat"
+)
+
+// Seviye durumları (dashboard için)
+const (
+	LevelStatePending  = "pending"
+	LevelStateExecuted = "executed"
+	LevelStateInactive = "inactive"
+)
+
+// GridVisualization - harici dashboard'lar için anlık grid görüntüsü
+type GridVisualization struct {
+	BasePrice    float64    `json:"base_price"`
+	Levels       []LevelViz `json:"levels"`
+	CurrentPrice float64    `json:"current_price"`
+	Timestamp    int64      `json:"timestamp"`
+}
+
+// LevelViz - tek seviyenin görselleştirme verisi
+type LevelViz struct {
+	Price    float64 `json:"price"`
+	Type     string  `json:"type"`
+	State    string  `json:"state"`
+	Distance float64 `json:"distance"` // güncel fiyata uzaklık (%)
+}
+
+// BuildVisualization - mevcut grid durumundan görselleştirme verisi üretir
+func BuildVisualization(s *strat.StratJob, basePrice float64, levels *GridLevelMap) GridVisualization {
+	currentPrice := s.Env.Close.Last(0)
+	viz := GridVisualization{
+		BasePrice:    basePrice,
+		CurrentPrice: currentPrice,
+		Timestamp:    s.Env.BarTime,
+	}
+	levels.Range(func(name string, level GridLevel) bool {
+		state := LevelStatePending
+		if !level.Active {
+			state = LevelStateInactive
+		} else if level.Executed {
+			state = LevelStateExecuted
+		}
+		distance := 0.0
+		if currentPrice > 0 {
+			distance = (level.Price - currentPrice) / currentPrice * 100
+		}
+		viz.Levels = append(viz.Levels, LevelViz{
+			Price:    level.Price,
+			Type:     level.Type,
+			State:    state,
+			Distance: distance,
+		})
+		return true
+	})
+	return viz
+}
+
+// writeVisualization - JSON çıktıyı dosyaya ya da "unix://" önekli sokete yazar
+func writeVisualization(target string, viz GridVisualization) error {
+	data, err := json.Marshal(viz)
+	if err != nil {
+		return err
+	}
+	if sockPath, ok := strings.CutPrefix(target, "unix://"); ok {
+		conn, err := net.Dial("unix", sockPath)
+		if err != nil {
+			return fmt.Errorf("dial %s: %w", sockPath, err)
+		}
+		defer conn.Close()
+		_, err = conn.Write(append(data, '\n'))
+		return err
+	}
+	return writeFileAtomic(target, data)
+}
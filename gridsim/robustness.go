@@ -0,0 +1,60 @@
+package gridsim
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RobustnessReport - parametre sarsıntısı altında simülasyon Sharpe dağılımı
+type RobustnessReport struct {
+	Iterations      int
+	MeanSharpe      float64
+	SharpeStdDev    float64
+	WorstSharpe     float64
+	BestSharpe      float64
+	RobustnessScore float64 // Sharpe'ı pozitif çıkan koşuların yüzdesi; %70 üzeri aşırı uyum olmadığına işaret eder
+}
+
+// RobustnessTest - cfg'nin sayısal parametrelerini her iterasyonda ±perturbPct% rastgele
+// sarsıp bars üzerinde SimulateGrid çalıştırır. Mode, PositionCost ve BarSecs sabit kalır.
+// GridPro banbot motoru olmadan backtest edilemediğinden pol yerine Config sarsılır.
+func RobustnessTest(bars []Bar, cfg Config, perturbPct float64, iterations int, rng *rand.Rand) RobustnessReport {
+	report := RobustnessReport{Iterations: iterations}
+	if iterations < 1 || rng == nil {
+		return report
+	}
+	perturb := func(value float64) float64 {
+		return value * (1 + (rng.Float64()*2-1)*perturbPct/100)
+	}
+	sharpes := make([]float64, 0, iterations)
+	positive := 0
+	for i := 0; i < iterations; i++ {
+		run := cfg
+		run.GridCount = max(int(math.Round(perturb(float64(cfg.GridCount)))), 1)
+		run.SpacingPct = perturb(cfg.SpacingPct)
+		run.ATRPeriod = max(int(math.Round(perturb(float64(cfg.ATRPeriod)))), 2)
+		run.ATRMultiplier = perturb(cfg.ATRMultiplier)
+		run.StopLossATR = perturb(cfg.StopLossATR)
+		run.TakeProfitATR = perturb(cfg.TakeProfitATR)
+		sharpe := SimulateGrid(bars, run).Sharpe
+		if math.IsNaN(sharpe) {
+			sharpe = 0
+		}
+		if sharpe > 0 {
+			positive++
+		}
+		sharpes = append(sharpes, sharpe)
+	}
+
+	report.WorstSharpe, report.BestSharpe = sharpes[0], sharpes[0]
+	for _, sharpe := range sharpes[1:] {
+		report.WorstSharpe = math.Min(report.WorstSharpe, sharpe)
+		report.BestSharpe = math.Max(report.BestSharpe, sharpe)
+	}
+	report.MeanSharpe, report.SharpeStdDev = meanStd(sharpes)
+	if len(sharpes) == 1 {
+		report.MeanSharpe = sharpes[0]
+	}
+	report.RobustnessScore = float64(positive) / float64(iterations) * 100
+	return report
+}
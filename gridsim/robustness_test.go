@@ -0,0 +1,78 @@
+package gridsim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRobustnessTest(t *testing.T) {
+	bars := oscillatingBars(300, 100, 3)
+	cfg := sensitivityConfig()
+	base := SimulateGrid(bars, cfg).Sharpe
+	tests := []struct {
+		name       string
+		perturbPct float64
+		iterations int
+		rng        *rand.Rand
+		check      func(t *testing.T, r RobustnessReport)
+	}{
+		{
+			name: "no iterations", iterations: 0, rng: rand.New(rand.NewSource(1)),
+			check: func(t *testing.T, r RobustnessReport) {
+				if r != (RobustnessReport{}) {
+					t.Errorf("report = %+v, want zero", r)
+				}
+			},
+		},
+		{
+			name: "nil rng", iterations: 5,
+			check: func(t *testing.T, r RobustnessReport) {
+				if r.MeanSharpe != 0 || r.RobustnessScore != 0 {
+					t.Errorf("report = %+v, want only Iterations set", r)
+				}
+			},
+		},
+		{
+			// Sarsıntı yoksa her koşu base ayarıyla aynıdır
+			name: "zero perturbation", perturbPct: 0, iterations: 4, rng: rand.New(rand.NewSource(1)),
+			check: func(t *testing.T, r RobustnessReport) {
+				for name, got := range map[string]float64{"mean": r.MeanSharpe, "worst": r.WorstSharpe, "best": r.BestSharpe} {
+					if math.Abs(got-base) > 1e-9 {
+						t.Errorf("%s Sharpe = %.6f, want base %.6f", name, got, base)
+					}
+				}
+				if r.SharpeStdDev > 1e-9 {
+					t.Errorf("SharpeStdDev = %.6f, want 0", r.SharpeStdDev)
+				}
+			},
+		},
+		{
+			name: "perturbed runs", perturbPct: 20, iterations: 20, rng: rand.New(rand.NewSource(7)),
+			check: func(t *testing.T, r RobustnessReport) {
+				if r.WorstSharpe > r.MeanSharpe || r.MeanSharpe > r.BestSharpe {
+					t.Errorf("worst %.4f <= mean %.4f <= best %.4f violated", r.WorstSharpe, r.MeanSharpe, r.BestSharpe)
+				}
+				if r.RobustnessScore < 0 || r.RobustnessScore > 100 {
+					t.Errorf("RobustnessScore = %.1f, want within 0..100", r.RobustnessScore)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := RobustnessTest(bars, cfg, tt.perturbPct, tt.iterations, tt.rng)
+			if report.Iterations != tt.iterations {
+				t.Errorf("Iterations = %d, want %d", report.Iterations, tt.iterations)
+			}
+			tt.check(t, report)
+		})
+	}
+
+	// Aynı seed aynı raporu üretir
+	a := RobustnessTest(bars, cfg, 10, 8, rand.New(rand.NewSource(3)))
+	b := RobustnessTest(bars, cfg, 10, 8, rand.New(rand.NewSource(3)))
+	if a != b {
+		t.Errorf("same seed, different reports:\n%+v\n%+v", a, b)
+	}
+}
@@ -0,0 +1,112 @@
+package gridsim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SensitivityResult - tek parametrenin değerlerine karşılık simülasyon Sharpe'ları
+type SensitivityResult struct {
+	Param      string
+	Values     []float64
+	Sharpes    []float64
+	Elasticity float64 // Sharpe'ın (değer / base değer) üzerindeki en küçük kareler eğimi
+}
+
+// sensitivityParams - Config'te değiştirilebilen parametreler, GridPro'daki pol.Def adlarıyla
+var sensitivityParams = map[string]func(cfg *Config, value float64){
+	"base_grid_count":  func(cfg *Config, v float64) { cfg.GridCount = max(int(math.Round(v)), 1) },
+	"base_spacing_pct": func(cfg *Config, v float64) { cfg.SpacingPct = v },
+	"atr_period":       func(cfg *Config, v float64) { cfg.ATRPeriod = max(int(math.Round(v)), 2) },
+	"atr_multiplier":   func(cfg *Config, v float64) { cfg.ATRMultiplier = v },
+	"stop_loss_atr":    func(cfg *Config, v float64) { cfg.StopLossATR = v },
+	"take_profit_atr":  func(cfg *Config, v float64) { cfg.TakeProfitATR = v },
+}
+
+// simParamValue - cfg'deki parametrenin güncel (base) değeri
+func simParamValue(cfg Config, param string) float64 {
+	switch param {
+	case "base_grid_count":
+		return float64(cfg.GridCount)
+	case "base_spacing_pct":
+		return cfg.SpacingPct
+	case "atr_period":
+		return float64(cfg.ATRPeriod)
+	case "atr_multiplier":
+		return cfg.ATRMultiplier
+	case "stop_loss_atr":
+		return cfg.StopLossATR
+	case "take_profit_atr":
+		return cfg.TakeProfitATR
+	}
+	return math.NaN()
+}
+
+// ComputeSensitivity - diğer parametreler cfg'de sabitken targetParam'ı values üzerinde gezdirip
+// her değer için SimulateGrid Sharpe'ını hesaplar. GridPro banbot motoru olmadan backtest
+// edilemediğinden pol yerine Config kullanılır.
+func ComputeSensitivity(bars []Bar, cfg Config, targetParam string, values []float64) (SensitivityResult, error) {
+	set, ok := sensitivityParams[targetParam]
+	if !ok {
+		return SensitivityResult{}, fmt.Errorf("unknown sensitivity parameter %q", targetParam)
+	}
+	base := simParamValue(cfg, targetParam)
+	res := SensitivityResult{Param: targetParam, Values: values, Sharpes: make([]float64, len(values))}
+	for i, value := range values {
+		run := cfg
+		set(&run, value)
+		res.Sharpes[i] = SimulateGrid(bars, run).Sharpe
+	}
+	if base != 0 {
+		normalized := make([]float64, len(values))
+		for i, value := range values {
+			normalized[i] = value / base
+		}
+		res.Elasticity = linearSlope(normalized, res.Sharpes)
+	}
+	return res, nil
+}
+
+// RankSensitivity - her parametreyi base değerinin 0.5x-1.5x aralığında gezdirir ve
+// |Elasticity|'ye göre en etkili topN parametreyi döndürür
+func RankSensitivity(bars []Bar, cfg Config, topN int) []SensitivityResult {
+	results := make([]SensitivityResult, 0, len(sensitivityParams))
+	for param := range sensitivityParams {
+		base := simParamValue(cfg, param)
+		values := []float64{base * 0.5, base * 0.75, base, base * 1.25, base * 1.5}
+		if res, err := ComputeSensitivity(bars, cfg, param, values); err == nil {
+			results = append(results, res)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if ei, ej := math.Abs(results[i].Elasticity), math.Abs(results[j].Elasticity); ei != ej {
+			return ei > ej
+		}
+		return results[i].Param < results[j].Param
+	})
+	if topN < len(results) {
+		results = results[:max(topN, 0)]
+	}
+	return results
+}
+
+// linearSlope - y'nin x üzerindeki en küçük kareler eğimi; x sabitse 0
+func linearSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
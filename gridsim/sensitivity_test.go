@@ -0,0 +1,80 @@
+package gridsim
+
+import (
+	"math"
+	"testing"
+)
+
+// sensitivityConfig - oscillatingBars üzerinde işlem üreten Fixed Spacing ayarı
+func sensitivityConfig() Config {
+	return Config{
+		Mode: ModeFixed, GridCount: 4, SpacingPct: 1, ATRPeriod: 5, ATRMultiplier: 1.5,
+		StopLossATR: 2, TakeProfitATR: 1, PositionCost: 100, BarSecs: 3600,
+	}
+}
+
+func TestComputeSensitivity(t *testing.T) {
+	bars := oscillatingBars(300, 100, 3)
+	values := []float64{0.75, 1.125, 1.5, 1.875, 2.25}
+	tests := []struct {
+		name        string
+		param       string
+		wantErr     bool
+		wantZero    bool // simülasyonun kullanmadığı parametre
+		wantVarying bool
+	}{
+		{name: "unknown parameter", param: "grid_count", wantErr: true},
+		// Fixed Spacing'de aralık yüzdeden gelir, atr_multiplier kullanılmaz
+		{name: "ignored parameter", param: "atr_multiplier", wantZero: true},
+		{name: "used parameter", param: "base_spacing_pct", wantVarying: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ComputeSensitivity(bars, sensitivityConfig(), tt.param, values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(res.Sharpes) != len(values) {
+				t.Fatalf("got %d Sharpes, want %d", len(res.Sharpes), len(values))
+			}
+			if tt.wantZero && math.Abs(res.Elasticity) > 1e-9 {
+				t.Errorf("Elasticity = %.6f, want 0 for an ignored parameter", res.Elasticity)
+			}
+			if tt.wantVarying && res.Elasticity == 0 {
+				t.Errorf("Elasticity = 0 for %s, Sharpes %v", tt.param, res.Sharpes)
+			}
+		})
+	}
+}
+
+func TestRankSensitivity(t *testing.T) {
+	bars := oscillatingBars(300, 100, 3)
+	tests := []struct {
+		topN, wantLen int
+	}{
+		{topN: -1, wantLen: 0},
+		{topN: 0, wantLen: 0},
+		{topN: 3, wantLen: 3},
+		{topN: 100, wantLen: len(sensitivityParams)},
+	}
+	for _, tt := range tests {
+		got := RankSensitivity(bars, sensitivityConfig(), tt.topN)
+		if len(got) != tt.wantLen {
+			t.Errorf("topN %d: got %d results, want %d", tt.topN, len(got), tt.wantLen)
+			continue
+		}
+		for i := 1; i < len(got); i++ {
+			if math.Abs(got[i].Elasticity) > math.Abs(got[i-1].Elasticity) {
+				t.Errorf("topN %d: %s ranked below %s", tt.topN, got[i-1].Param, got[i].Param)
+			}
+		}
+	}
+	// Kullanılmayan parametre tam listede en sonda olmalı
+	all := RankSensitivity(bars, sensitivityConfig(), 100)
+	if last := all[len(all)-1]; last.Param != "atr_multiplier" || last.Elasticity != 0 {
+		t.Errorf("last ranked = %s (%.4f), want atr_multiplier with 0", last.Param, last.Elasticity)
+	}
+}
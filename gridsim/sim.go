@@ -0,0 +1,300 @@
+// Package gridsim - GridPro'nun seviye kurulumu, tetikleme ve ATR stop/hedef kurallarının
+// borsa motoru olmadan simülasyonu. banbot'a bağımlı değildir; cmd/gridbench gibi araçlar
+// strateji paketini (ve onun banbot kayıtlarını) yüklemeden kullanabilir.
+package gridsim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Simüle edilen grid modları; değerler dnm.GridMode* (grid_mode) ile aynıdır
+const (
+	ModeFixed   = "Fixed Spacing"
+	ModeATR     = "ATR Based"
+	ModeEvenOdd = "Even Odd" // çift index alış, tek index satış; iki taraf da base'in iki yanında
+)
+
+// Base fiyat kaynakları (Config.Anchor)
+const (
+	AnchorStatic = ""      // base ilk fiyatta sabit kalır (GridPro varsayılanı)
+	AnchorClose  = "close" // grid boşken fiyat en dış seviyenin ötesine geçerse base kapanışa taşınır
+	AnchorPOC    = "poc"   // base son ProfileBars barın TPO point of control'üdür, grid boşken aynı koşulda yenilenir
+)
+
+// profileBinPct - TPO profilinde fiyat kutusu genişliği (kapanışın %'si)
+const profileBinPct = 0.1
+
+// MaxGridLevels - GridPro'da bir taraftaki en fazla grid seviyesi (Even Odd hariç)
+const MaxGridLevels = 8
+
+// Bar - simülasyon için OHLC bar
+type Bar struct {
+	Open, High, Low, Close float64
+}
+
+// Config - simülasyon ayarları
+type Config struct {
+	Mode          string
+	GridCount     int
+	SpacingPct    float64 // Fixed / Even Odd
+	ATRPeriod     int
+	ATRMultiplier float64 // ATR Based
+	StopLossATR   float64
+	TakeProfitATR float64
+	PositionCost  float64 // seviye başına quote tutarı
+	BarSecs       int64   // Sharpe yıllıklandırması için (bar başına getiri serisi)
+	Anchor        string  // AnchorStatic, AnchorClose ya da AnchorPOC
+	ProfileBars   int     // AnchorPOC profil penceresi; 0 ise mevcut tüm barlar
+}
+
+// Result - simülasyon özeti
+type Result struct {
+	Trades      int
+	Wins        int
+	WinRate     float64
+	Sharpe      float64
+	MaxDrawdown float64 // gerçekleşmiş PnL zirvesinden en büyük düşüş
+	TotalPnL    float64
+	Recenters   int // base'in yeniden konumlandırılma sayısı
+}
+
+// level - simülasyondaki grid seviyesi
+type level struct {
+	name  string
+	short bool
+	price float64
+}
+
+type position struct {
+	level  string
+	short  bool
+	entry  float64
+	amount float64
+}
+
+// layoutLevels - GridPro'nun seviye düzeni. Fixed/ATR'de alışlar base - i*spacing, satışlar base + i*spacing;
+// Even Odd'da base'in iki yanında 2*count seviye dizilir (en alttan 1..2*count), çift index alış, tek index satış.
+func layoutLevels(mode string, base, spacing float64, count int) []level {
+	if mode != ModeEvenOdd {
+		res := make([]level, 0, 2*count)
+		for i := 1; i <= count; i++ {
+			res = append(res,
+				level{name: fmt.Sprintf("B%d", i), price: base - spacing*float64(i)},
+				level{name: fmt.Sprintf("S%d", i), short: true, price: base + spacing*float64(i)})
+		}
+		return res
+	}
+	res := make([]level, 0, 2*count)
+	for index := 1; index <= 2*count; index++ {
+		offset := index - count - 1
+		if index > count {
+			offset = index - count
+		}
+		lvl := level{name: fmt.Sprintf("S%d", index), short: true, price: base + spacing*float64(offset)}
+		if index%2 == 0 {
+			lvl = level{name: fmt.Sprintf("B%d", index), price: lvl.price}
+		}
+		res = append(res, lvl)
+	}
+	return res
+}
+
+// SimulateGrid - seviye kurulumu, tetikleme ve ATR stop/hedef kurallarını verilen barlar üzerinde çalıştırır.
+// Pozisyonu kapanan seviye sonraki barda yeniden tetiklenebilir. Sharpe, bar başına equity değişiminin
+// (gerçekleşmiş + açık PnL) grid bütçesine oranından hesaplanır. Filtreler ve emir motoru simüle edilmez.
+func SimulateGrid(bars []Bar, cfg Config) Result {
+	var res Result
+	if len(bars) <= cfg.ATRPeriod || cfg.ATRPeriod < 1 {
+		return res
+	}
+	basePrice := anchorPrice(bars[:cfg.ATRPeriod+1], cfg)
+	count := cfg.GridCount
+	if cfg.Mode != ModeEvenOdd {
+		count = min(count, MaxGridLevels)
+	}
+	executed := make(map[string]bool)
+	returns := make([]float64, 0, len(bars)-cfg.ATRPeriod)
+	var positions []position
+	peak, prevEquity := 0.0, 0.0
+	for i := cfg.ATRPeriod; i < len(bars); i++ {
+		bar := bars[i]
+		atr := simpleATR(bars[i-cfg.ATRPeriod : i+1])
+		spacing := bar.Close * cfg.SpacingPct / 100
+		if cfg.Mode == ModeATR {
+			spacing = atr * cfg.ATRMultiplier
+		}
+		if cfg.Anchor != AnchorStatic && len(positions) == 0 && spacing > 0 &&
+			math.Abs(bar.Close-basePrice) > spacing*float64(count) {
+			basePrice = anchorPrice(bars[:i+1], cfg)
+			res.Recenters++
+		}
+		levels := layoutLevels(cfg.Mode, basePrice, spacing, count)
+
+		for _, lvl := range levels {
+			if executed[lvl.name] {
+				continue
+			}
+			triggered := bar.Low <= lvl.price
+			if lvl.short {
+				triggered = bar.High >= lvl.price
+			}
+			if cfg.Mode == ModeEvenOdd {
+				triggered = bar.Low <= lvl.price && bar.High >= lvl.price
+			}
+			if !triggered {
+				continue
+			}
+			executed[lvl.name] = true
+			// Bar seviyenin ötesinde açıldıysa (gap ya da yeniden kurulan seviye) dolum açılış fiyatından olur
+			entry := lvl.price
+			if bar.Open > 0 && !lvl.short {
+				entry = math.Min(lvl.price, bar.Open)
+			} else if bar.Open > 0 {
+				entry = math.Max(lvl.price, bar.Open)
+			}
+			positions = append(positions, position{
+				level:  lvl.name,
+				short:  lvl.short,
+				entry:  entry,
+				amount: cfg.PositionCost / entry,
+			})
+		}
+
+		open := positions[:0]
+		for _, pos := range positions {
+			stop := pos.entry - atr*cfg.StopLossATR
+			target := pos.entry + atr*cfg.TakeProfitATR
+			hit := bar.Close <= stop || bar.Close >= target
+			if pos.short {
+				stop = pos.entry + atr*cfg.StopLossATR
+				target = pos.entry - atr*cfg.TakeProfitATR
+				hit = bar.Close >= stop || bar.Close <= target
+			}
+			if !hit {
+				open = append(open, pos)
+				continue
+			}
+			pnl := (bar.Close - pos.entry) * pos.amount
+			if pos.short {
+				pnl = -pnl
+			}
+			res.Trades++
+			if pnl > 0 {
+				res.Wins++
+			}
+			res.TotalPnL += pnl
+			peak = math.Max(peak, res.TotalPnL)
+			res.MaxDrawdown = math.Max(res.MaxDrawdown, peak-res.TotalPnL)
+			executed[pos.level] = false // seviye yeniden emir açabilir
+		}
+		positions = open
+
+		equity := res.TotalPnL
+		for _, pos := range positions {
+			if pos.short {
+				equity += (pos.entry - bar.Close) * pos.amount
+			} else {
+				equity += (bar.Close - pos.entry) * pos.amount
+			}
+		}
+		if budget := cfg.PositionCost * float64(len(levels)); budget > 0 {
+			returns = append(returns, (equity-prevEquity)/budget)
+		}
+		prevEquity = equity
+	}
+	if res.Trades > 0 {
+		res.WinRate = float64(res.Wins) / float64(res.Trades)
+	}
+	res.Sharpe = sharpe(returns, cfg.BarSecs)
+	return res
+}
+
+// anchorPrice - bars'ın sonunda geçerli base fiyatı: AnchorPOC'de profil POC'u, diğerlerinde son kapanış
+func anchorPrice(bars []Bar, cfg Config) float64 {
+	last := bars[len(bars)-1].Close
+	if cfg.Anchor != AnchorPOC {
+		return last
+	}
+	if cfg.ProfileBars > 0 && len(bars) > cfg.ProfileBars {
+		bars = bars[len(bars)-cfg.ProfileBars:]
+	}
+	return pointOfControl(bars, last*profileBinPct/100)
+}
+
+// pointOfControl - TPO profilinde en çok barın geçtiği fiyat kutusunun ortası. Her bar low-high
+// aralığının kapsadığı her kutuya bir sayım ekler; eşitlikte son kapanışa en yakın kutu seçilir.
+func pointOfControl(bars []Bar, binSize float64) float64 {
+	if len(bars) == 0 {
+		return 0
+	}
+	last := bars[len(bars)-1].Close
+	if binSize <= 0 {
+		return last
+	}
+	counts := make(map[int]int)
+	for _, bar := range bars {
+		for bin := int(math.Floor(bar.Low / binSize)); bin <= int(math.Floor(bar.High/binSize)); bin++ {
+			counts[bin]++
+		}
+	}
+	lastBin := int(math.Floor(last / binSize))
+	best, bestCount := lastBin, 0
+	for bin, count := range counts {
+		closer := abs(bin-lastBin) < abs(best-lastBin) || (abs(bin-lastBin) == abs(best-lastBin) && bin < best)
+		if count > bestCount || (count == bestCount && closer) {
+			best, bestCount = bin, count
+		}
+	}
+	return (float64(best) + 0.5) * binSize
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// simpleATR - barların (ilki yalnızca önceki kapanış için) basit ortalama true range'i
+func simpleATR(bars []Bar) float64 {
+	if len(bars) < 2 {
+		return 0
+	}
+	sum := 0.0
+	for i := 1; i < len(bars); i++ {
+		prevClose := bars[i-1].Close
+		sum += math.Max(bars[i].High-bars[i].Low,
+			math.Max(math.Abs(bars[i].High-prevClose), math.Abs(bars[i].Low-prevClose)))
+	}
+	return sum / float64(len(bars)-1)
+}
+
+// sharpe - bar başına getirilerin yıllıklandırılmış Sharpe'ı; faktör sqrt(365 * günlük bar sayısı)
+func sharpe(returns []float64, barSecs int64) float64 {
+	mean, std := meanStd(returns)
+	if std == 0 {
+		return 0
+	}
+	factor := 1.0
+	if barSecs > 0 {
+		factor = math.Sqrt(365 * 86400 / float64(barSecs))
+	}
+	return mean / std * factor
+}
+
+// meanStd - örneklem ortalaması ve standart sapması
+func meanStd(values []float64) (mean, std float64) {
+	n := len(values)
+	if n < 2 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(n-1))
+}
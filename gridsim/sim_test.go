@@ -0,0 +1,154 @@
+package gridsim
+
+import (
+	"math"
+	"testing"
+)
+
+// oscillatingBars - center etrafında amplitude genlikle salınan, açılışı önceki kapanış olan barlar
+func oscillatingBars(n int, center, amplitude float64) []Bar {
+	bars := make([]Bar, n)
+	prev := center
+	for i := range bars {
+		price := center + amplitude*math.Sin(float64(i)/3)
+		bars[i] = Bar{Open: prev, High: math.Max(prev, price) + 0.2, Low: math.Min(prev, price) - 0.2, Close: price}
+		prev = price
+	}
+	return bars
+}
+
+func TestLayoutLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		count int
+		want  map[string]float64
+	}{
+		{name: "fixed", mode: ModeFixed, count: 2, want: map[string]float64{"B1": 99, "B2": 98, "S1": 101, "S2": 102}},
+		{name: "even odd", mode: ModeEvenOdd, count: 2, want: map[string]float64{"S1": 98, "B2": 99, "S3": 101, "B4": 102}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := layoutLevels(tt.mode, 100, 1, tt.count)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d levels, want %d", len(got), len(tt.want))
+			}
+			for _, lvl := range got {
+				price, ok := tt.want[lvl.name]
+				if !ok || math.Abs(lvl.price-price) > 1e-9 {
+					t.Errorf("level %s at %.2f, want %.2f (known %v)", lvl.name, lvl.price, price, ok)
+				}
+				if lvl.short != (lvl.name[0] == 'S') {
+					t.Errorf("level %s short = %v", lvl.name, lvl.short)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateGrid(t *testing.T) {
+	cfg := Config{
+		Mode: ModeFixed, GridCount: 2, SpacingPct: 1, ATRPeriod: 5,
+		StopLossATR: 3, TakeProfitATR: 1, PositionCost: 100, BarSecs: 3600,
+	}
+	flat := make([]Bar, 50)
+	for i := range flat {
+		flat[i] = Bar{Open: 100, High: 100, Low: 100, Close: 100}
+	}
+	tests := []struct {
+		name      string
+		bars      []Bar
+		minTrades int
+		maxTrades int
+	}{
+		{name: "too few bars", bars: flat[:5]},
+		{name: "flat market never triggers", bars: flat},
+		// 4 seviyeden fazla işlem, kapanan seviyelerin yeniden kurulduğunu gösterir
+		{name: "oscillation re-arms levels", bars: oscillatingBars(300, 100, 3), minTrades: 5, maxTrades: math.MaxInt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := SimulateGrid(tt.bars, cfg)
+			if res.Trades < tt.minTrades || res.Trades > tt.maxTrades {
+				t.Errorf("Trades = %d, want [%d, %d]", res.Trades, tt.minTrades, tt.maxTrades)
+			}
+			if res.Trades == 0 && (res.TotalPnL != 0 || res.Sharpe != 0) {
+				t.Errorf("no trades but TotalPnL=%.4f Sharpe=%.4f", res.TotalPnL, res.Sharpe)
+			}
+			if res.Wins > res.Trades || res.MaxDrawdown < 0 {
+				t.Errorf("inconsistent result %+v", res)
+			}
+		})
+	}
+}
+
+// 100'de 30 bar, sonra 150'de sabit. AnchorClose base'i sıçrama barında 150'ye taşır; AnchorPOC'de 5 barlık
+// profil önce 100'de kalır, pencere 150 barlarıyla dolunca base ikinci kez taşınır
+func TestSimulateGridRecenter(t *testing.T) {
+	bars := make([]Bar, 60)
+	for i := range bars {
+		price := 100.0
+		if i >= 30 {
+			price = 150
+		}
+		bars[i] = Bar{Open: price, High: price, Low: price, Close: price}
+	}
+	tests := []struct {
+		anchor        string
+		wantRecenters int
+	}{
+		{anchor: AnchorStatic, wantRecenters: 0},
+		{anchor: AnchorClose, wantRecenters: 1},
+		{anchor: AnchorPOC, wantRecenters: 2},
+	}
+	for _, tt := range tests {
+		t.Run("anchor "+tt.anchor, func(t *testing.T) {
+			cfg := Config{
+				Mode: ModeFixed, GridCount: 2, SpacingPct: 1, ATRPeriod: 5,
+				StopLossATR: 2, TakeProfitATR: 3, PositionCost: 100, BarSecs: 3600,
+				Anchor: tt.anchor, ProfileBars: 5,
+			}
+			if res := SimulateGrid(bars, cfg); res.Recenters != tt.wantRecenters {
+				t.Errorf("Recenters = %d, want %d (%+v)", res.Recenters, tt.wantRecenters, res)
+			}
+		})
+	}
+}
+
+func TestPointOfControl(t *testing.T) {
+	tests := []struct {
+		name string
+		bars []Bar
+		bin  float64
+		want float64
+	}{
+		{name: "empty", want: 0},
+		{name: "no bin size", bars: []Bar{{Low: 99, High: 101, Close: 100}}, want: 100},
+		{
+			name: "most visited bin",
+			bars: []Bar{
+				{Low: 100, High: 103, Close: 102},
+				{Low: 101, High: 101.5, Close: 101},
+				{Low: 101, High: 104, Close: 104},
+			},
+			bin:  1,
+			want: 101.5,
+		},
+		{
+			name: "tie resolved toward last close",
+			bars: []Bar{
+				{Low: 100, High: 100.5, Close: 100},
+				{Low: 105, High: 105.5, Close: 105},
+			},
+			bin:  1,
+			want: 105.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pointOfControl(tt.bars, tt.bin); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("pointOfControl = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}